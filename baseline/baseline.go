@@ -0,0 +1,156 @@
+// Package baseline records existing findings so that only new ones are reported.
+package baseline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Aadi-IRON/agni/detectors"
+)
+
+// formatVersion is the version of the baseline file format.
+const formatVersion = 1
+
+// DefaultFile is the baseline file name used when none is given.
+const DefaultFile = ".agni-baseline.json"
+
+// Entry is a group of identical findings recorded in a baseline.
+type Entry struct {
+	Fingerprint string `json:"fingerprint"`
+	Rule        string `json:"rule"`
+	// File is relative to the scan root, using forward slashes.
+	File string `json:"file"`
+	// Symbol is the declaration enclosing the finding, such as "(*Server).Start".
+	Symbol string `json:"symbol,omitempty"`
+	// Snippet is the whitespace-normalized source line of the finding.
+	Snippet string `json:"snippet"`
+	Message string `json:"message"`
+	// Count is the number of findings sharing the fingerprint.
+	Count int `json:"count"`
+}
+
+// Baseline is the content of a baseline file.
+type Baseline struct {
+	Version int     `json:"version"`
+	Entries []Entry `json:"entries"`
+}
+
+// Create builds a baseline from the findings of a run rooted at root.
+func Create(root string, results []detectors.Result) *Baseline {
+	fingerprints := newFingerprinter(root)
+	byFingerprint := make(map[string]*Entry)
+	for _, result := range results {
+		for _, finding := range result.Findings {
+			entry := fingerprints.entry(finding)
+			if existing, ok := byFingerprint[entry.Fingerprint]; ok {
+				existing.Count++
+				continue
+			}
+			entry.Count = 1
+			byFingerprint[entry.Fingerprint] = &entry
+		}
+	}
+
+	baseline := &Baseline{Version: formatVersion, Entries: []Entry{}}
+	for _, entry := range byFingerprint {
+		baseline.Entries = append(baseline.Entries, *entry)
+	}
+	sort.Slice(baseline.Entries, func(i, j int) bool {
+		left, right := baseline.Entries[i], baseline.Entries[j]
+		if left.File != right.File {
+			return left.File < right.File
+		}
+		if left.Rule != right.Rule {
+			return left.Rule < right.Rule
+		}
+		return left.Fingerprint < right.Fingerprint
+	})
+	return baseline
+}
+
+// Load reads a baseline file.
+func Load(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading baseline %s: %v", path, err)
+	}
+	var baseline Baseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("error parsing baseline %s: %v", path, err)
+	}
+	if baseline.Version != formatVersion {
+		return nil, fmt.Errorf("baseline %s has unsupported version %d", path, baseline.Version)
+	}
+	return &baseline, nil
+}
+
+// Save writes the baseline to path.
+func (baseline *Baseline) Save(path string) error {
+	data, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Len returns the number of findings recorded in the baseline.
+func (baseline *Baseline) Len() int {
+	total := 0
+	for _, entry := range baseline.Entries {
+		total += entry.Count
+	}
+	return total
+}
+
+// Filter removes the findings recorded in the baseline from results and
+// returns how many were removed. Each entry hides at most Count findings, so
+// new occurrences of an already known problem are still reported.
+func (baseline *Baseline) Filter(root string, results []detectors.Result) int {
+	remaining := make(map[string]int, len(baseline.Entries))
+	for _, entry := range baseline.Entries {
+		remaining[entry.Fingerprint] += entry.Count
+	}
+
+	fingerprints := newFingerprinter(root)
+	removed := 0
+	for idx := range results {
+		kept := results[idx].Findings[:0]
+		for _, finding := range results[idx].Findings {
+			fingerprint := fingerprints.entry(finding).Fingerprint
+			if remaining[fingerprint] > 0 {
+				remaining[fingerprint]--
+				removed++
+				continue
+			}
+			kept = append(kept, finding)
+		}
+		results[idx].Findings = kept
+	}
+	return removed
+}
+
+// fingerprint hashes the parts of an entry that identify a finding.
+func fingerprint(entry Entry) string {
+	hash := sha256.New()
+	for _, part := range []string{entry.Rule, entry.File, entry.Symbol, entry.Snippet} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:16]
+}
+
+// relativePath returns file relative to root with forward slashes, or file
+// itself when it lies outside root.
+func relativePath(root, file string) string {
+	relative, err := filepath.Rel(root, file)
+	if err != nil || strings.HasPrefix(relative, "..") {
+		return filepath.ToSlash(file)
+	}
+	return filepath.ToSlash(relative)
+}
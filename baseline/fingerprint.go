@@ -0,0 +1,113 @@
+package baseline
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"strings"
+
+	"github.com/Aadi-IRON/agni/detectors"
+)
+
+// sourceFile is a parsed file used to compute fingerprints.
+type sourceFile struct {
+	lines []string
+	fset  *token.FileSet
+	node  *ast.File
+}
+
+// fingerprinter computes baseline entries for findings, caching the files it
+// has read.
+type fingerprinter struct {
+	root  string
+	files map[string]*sourceFile
+}
+
+func newFingerprinter(root string) *fingerprinter {
+	return &fingerprinter{root: root, files: make(map[string]*sourceFile)}
+}
+
+// entry returns the baseline entry of a single finding, with a zero Count.
+func (fingerprints *fingerprinter) entry(finding detectors.Finding) Entry {
+	entry := Entry{
+		Rule:    finding.RuleID,
+		File:    relativePath(fingerprints.root, finding.File),
+		Message: finding.Message,
+	}
+	source := fingerprints.load(finding.File)
+	if source != nil && finding.Line > 0 && finding.Line <= len(source.lines) {
+		entry.Snippet = strings.Join(strings.Fields(source.lines[finding.Line-1]), " ")
+		entry.Symbol = enclosingSymbol(source, finding.Line)
+	} else {
+		// Without a source line, the message is the most stable description
+		entry.Snippet = finding.Message
+	}
+	entry.Fingerprint = fingerprint(entry)
+	return entry
+}
+
+// load reads and parses a file, returning nil when it cannot be read.
+func (fingerprints *fingerprinter) load(path string) *sourceFile {
+	if source, ok := fingerprints.files[path]; ok {
+		return source
+	}
+	var source *sourceFile
+	if src, err := os.ReadFile(path); err == nil {
+		source = &sourceFile{lines: strings.Split(string(src), "\n"), fset: token.NewFileSet()}
+		if strings.HasSuffix(path, ".go") {
+			// A file that does not parse still has usable snippets
+			source.node, _ = parser.ParseFile(source.fset, path, src, parser.SkipObjectResolution)
+		}
+	}
+	fingerprints.files[path] = source
+	return source
+}
+
+// enclosingSymbol names the top-level declaration that contains a line.
+func enclosingSymbol(source *sourceFile, line int) string {
+	if source.node == nil {
+		return ""
+	}
+	for _, decl := range source.node.Decls {
+		if line < source.fset.Position(decl.Pos()).Line || line > source.fset.Position(decl.End()).Line {
+			continue
+		}
+		switch typed := decl.(type) {
+		case *ast.FuncDecl:
+			if typed.Recv != nil && len(typed.Recv.List) > 0 {
+				return "(" + receiverType(typed.Recv.List[0].Type) + ")." + typed.Name.Name
+			}
+			return typed.Name.Name
+		case *ast.GenDecl:
+			for _, spec := range typed.Specs {
+				if line < source.fset.Position(spec.Pos()).Line || line > source.fset.Position(spec.End()).Line {
+					continue
+				}
+				switch typedSpec := spec.(type) {
+				case *ast.TypeSpec:
+					return typedSpec.Name.Name
+				case *ast.ValueSpec:
+					return typedSpec.Names[0].Name
+				}
+			}
+			return typed.Tok.String()
+		}
+	}
+	return ""
+}
+
+// receiverType formats a method receiver type such as *Server or List[T].
+func receiverType(expr ast.Expr) string {
+	switch typed := expr.(type) {
+	case *ast.StarExpr:
+		return "*" + receiverType(typed.X)
+	case *ast.Ident:
+		return typed.Name
+	case *ast.IndexExpr:
+		return receiverType(typed.X)
+	case *ast.IndexListExpr:
+		return receiverType(typed.X)
+	}
+	return "?"
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Aadi-IRON/agni/baseline"
+)
+
+// runBaseline implements "agni baseline create [flags] [paths...]".
+func runBaseline(args []string) int {
+	if len(args) == 0 || args[0] != "create" {
+		fmt.Fprintln(os.Stderr, "❌ Usage: agni baseline create [-o file] [flags] [paths...]")
+		return exitError
+	}
+
+	flags := flag.NewFlagSet("baseline create", flag.ContinueOnError)
+	analysis := addAnalysisFlags(flags)
+	outputPtr := flags.String("o", baseline.DefaultFile, "File to write the baseline to")
+	if err := flags.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitClean
+		}
+		return exitError
+	}
+
+	run, err := analysis.analyze(flags.Args(), true)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "❌", err)
+		return exitError
+	}
+	for _, result := range run.Results {
+		if result.Err != nil {
+			// A partial run would record an incomplete baseline
+			fmt.Fprintf(os.Stderr, "❌ Detector %s failed: %v\n", result.Detector.Name(), result.Err)
+			return exitError
+		}
+	}
+
+	snapshot := baseline.Create(run.Root, run.Results)
+	if err := snapshot.Save(*outputPtr); err != nil {
+		fmt.Fprintln(os.Stderr, "❌ Error writing baseline:", err)
+		return exitError
+	}
+	fmt.Printf("📌 Baseline with %d finding(s) written to %s\n", snapshot.Len(), *outputPtr)
+	return exitClean
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/Aadi-IRON/agni/baseline"
+	"github.com/Aadi-IRON/agni/config"
+	"github.com/Aadi-IRON/agni/detectors"
+	"github.com/Aadi-IRON/agni/gitdiff"
+	"github.com/Aadi-IRON/agni/report"
+)
+
+// analysisFlags are the flags shared by the commands that run detectors.
+type analysisFlags struct {
+	dir    *string
+	only   *string
+	skip   *string
+	config *string
+	jobs   *int
+}
+
+// addAnalysisFlags registers the shared analysis flags on flags.
+func addAnalysisFlags(flags *flag.FlagSet) *analysisFlags {
+	return &analysisFlags{
+		// Optional: Allow custom directory via flag
+		dir:    flags.String("dir", "", "Directory to run Agni checks in (same as passing it as a path)"),
+		only:   flags.String("only", "", "Comma-separated detectors to run, in the given order"),
+		skip:   flags.String("skip", "", "Comma-separated detectors to skip"),
+		config: flags.String("config", "", "Configuration file (default: .agni.yaml, .agni.yml or .agni.json found from the scanned directory upward)"),
+		jobs:   flags.Int("j", runtime.GOMAXPROCS(0), "Number of detectors and files analyzed in parallel"),
+	}
+}
+
+// analyze runs the selected detectors against every path and merges the
+// results. When verbose is set, progress lines are printed to stdout, and
+// progress indicators to stderr when it is a terminal.
+func (analysis *analysisFlags) analyze(paths []string, verbose bool) (*report.Run, error) {
+	selected, err := detectors.Select(detectors.ParseNames(*analysis.only), detectors.ParseNames(*analysis.skip))
+	if err != nil {
+		return nil, fmt.Errorf("error selecting detectors: %v", err)
+	}
+
+	if *analysis.dir != "" {
+		paths = append([]string{*analysis.dir}, paths...)
+	}
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	absPaths := make([]string, 0, len(paths))
+	for _, path := range paths {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return nil, fmt.Errorf("error getting absolute path: %v", err)
+		}
+		absPaths = append(absPaths, absPath)
+	}
+
+	run := &report.Run{Root: commonDir(absPaths), Version: version(), Start: time.Now()}
+	var perPath [][]detectors.Result
+	for _, absPath := range absPaths {
+		project, err := loadProject(absPath, *analysis.config)
+		if err != nil {
+			return nil, fmt.Errorf("error loading configuration: %v", err)
+		}
+		pathDetectors := selected
+		// An explicit -only list wins over detectors disabled in the configuration
+		if *analysis.only == "" {
+			pathDetectors = detectors.Enabled(selected, project)
+		}
+		if verbose {
+			fmt.Println("🔥 Running Agni checks in:", absPath)
+			if project.Path != "" {
+				fmt.Println("⚙️  Using configuration:", project.Path)
+			}
+		}
+		options := detectors.Options{Project: project, Jobs: *analysis.jobs}
+		if verbose && isTerminal(os.Stderr) {
+			options.Loading = config.NewSpinner("📦 Parsing")
+			options.Progress = config.NewProgressBar(len(pathDetectors), "🔍 Detectors")
+		}
+		perPath = append(perPath, detectors.Run(absPath, pathDetectors, options))
+	}
+	run.Results = mergeResults(perPath)
+	run.Duration = time.Since(run.Start)
+	return run, nil
+}
+
+// runCheck implements "agni check [flags] [paths...]".
+func runCheck(args []string) int {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	analysis := addAnalysisFlags(flags)
+	listPtr := flags.Bool("list", false, "List the available detectors and exit")
+	formatPtr := flags.String("format", "text", "Output format: text, json or sarif")
+	failOnPtr := flags.String("fail-on", "info", "Exit with 1 when findings at or above this severity exist: info, warning, error or none")
+	baselinePtr := flags.String("baseline", "", "Only report findings that are not recorded in this baseline file")
+	newFromRevPtr := flags.String("new-from-rev", "", "Only report findings on lines changed since this git revision")
+	diffPtr := flags.String("diff", "", "Only report findings on lines changed by this unified diff/patch file")
+	var fixes fixMode
+	flags.Var(&fixes, "fix", "Apply the safe fixes of the reported findings; -fix=diff prints them as a patch instead")
+	limits := ruleLimits{}
+	flags.Var(limits, "max", "Allow up to N findings of a rule before failing, as rule=N (repeatable)")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitClean
+		}
+		return exitError
+	}
+
+	if *listPtr {
+		return runList(nil)
+	}
+
+	reporter, err := report.New(*formatPtr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "❌ Error:", err)
+		return exitError
+	}
+
+	runGate, err := newGate(*failOnPtr, limits)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "❌ Error:", err)
+		return exitError
+	}
+
+	var known *baseline.Baseline
+	if *baselinePtr != "" {
+		if known, err = baseline.Load(*baselinePtr); err != nil {
+			fmt.Fprintln(os.Stderr, "❌ Error:", err)
+			return exitError
+		}
+	}
+
+	if *newFromRevPtr != "" && *diffPtr != "" {
+		fmt.Fprintln(os.Stderr, "❌ Error: -new-from-rev and -diff cannot be used together")
+		return exitError
+	}
+
+	// Detectors always analyze the whole tree, since checks such as
+	// exported-but-internal need every package; only the report is narrowed.
+	run, err := analysis.analyze(flags.Args(), *formatPtr == "text" && fixes != "diff")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "❌", err)
+		return exitError
+	}
+	if *newFromRevPtr != "" || *diffPtr != "" {
+		var changes *gitdiff.Changes
+		if *newFromRevPtr != "" {
+			changes, err = gitdiff.FromRevision(run.Root, *newFromRevPtr)
+		} else {
+			changes, err = gitdiff.ParseFile(*diffPtr, run.Root)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "❌ Error reading changes:", err)
+			return exitError
+		}
+		changes.Filter(run.Results)
+	}
+	if known != nil {
+		run.Baselined = known.Filter(run.Root, run.Results)
+	}
+	if fixes != "" {
+		if !applyFixes(fixes, run) {
+			return exitError
+		}
+		// The patch is the output; a report would make it unusable
+		if fixes == "diff" {
+			return exitClean
+		}
+	}
+	if err := reporter.Report(os.Stdout, run); err != nil {
+		fmt.Fprintln(os.Stderr, "❌ Error writing report:", err)
+		return exitError
+	}
+	return runGate.exitCode(run.Results)
+}
+
+// loadProject loads the configuration file given with -config, or discovers
+// one from root upward, and validates the detector names it mentions.
+func loadProject(root, configFile string) (*config.Project, error) {
+	var project *config.Project
+	var err error
+	if configFile != "" {
+		project, err = config.LoadProjectFile(configFile)
+	} else {
+		project, err = config.LoadProject(root)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := detectors.CheckProject(project); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// mergeResults combines the results of running detectors against several
+// paths. Each path may enable different detectors, so results are matched by
+// detector name and ordered as the registry orders detectors.
+func mergeResults(perPath [][]detectors.Result) []detectors.Result {
+	if len(perPath) == 1 {
+		return perPath[0]
+	}
+	byName := make(map[string]*detectors.Result)
+	for _, results := range perPath {
+		for _, result := range results {
+			name := result.Detector.Name()
+			merged, ok := byName[name]
+			if !ok {
+				merged = &detectors.Result{Detector: result.Detector}
+				byName[name] = merged
+			}
+			merged.Findings = append(merged.Findings, result.Findings...)
+			merged.Err = errors.Join(merged.Err, result.Err)
+			merged.Duration += result.Duration
+		}
+	}
+	var merged []detectors.Result
+	for _, detector := range detectors.All() {
+		if result, ok := byName[detector.Name()]; ok {
+			detectors.SortFindings(result.Findings)
+			merged = append(merged, *result)
+		}
+	}
+	return merged
+}
+
+// isTerminal reports whether file is an interactive terminal rather than a
+// pipe or a regular file, as in CI logs.
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// commonDir returns the deepest directory containing every given path.
+func commonDir(paths []string) string {
+	common := paths[0]
+	for _, path := range paths[1:] {
+		for common != filepath.Dir(common) && path != common &&
+			!strings.HasPrefix(path, common+string(filepath.Separator)) {
+			common = filepath.Dir(common)
+		}
+	}
+	return common
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Aadi-IRON/agni/detectors"
+)
+
+// Exit codes returned by agni.
+const (
+	exitClean    = 0 // no findings at or above the failure threshold
+	exitFindings = 1 // findings at or above the threshold, or a rule limit exceeded
+	exitError    = 2 // invalid usage or an internal/analysis error
+)
+
+// failOnNone disables failing on findings altogether.
+const failOnNone = "none"
+
+// ruleLimits maps a rule to the number of findings it may produce before the
+// run fails. It implements flag.Value so -max can be repeated.
+type ruleLimits map[string]int
+
+func (limits ruleLimits) String() string {
+	var parts []string
+	for rule, limit := range limits {
+		parts = append(parts, fmt.Sprintf("%s=%d", rule, limit))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+func (limits ruleLimits) Set(value string) error {
+	for _, entry := range strings.Split(value, ",") {
+		rule, count, found := strings.Cut(strings.TrimSpace(entry), "=")
+		if !found || rule == "" {
+			return fmt.Errorf("invalid limit %q (expected rule=N)", entry)
+		}
+		limit, err := strconv.Atoi(count)
+		if err != nil || limit < 0 {
+			return fmt.Errorf("invalid limit %q: N must be a non-negative integer", entry)
+		}
+		if _, ok := detectors.Lookup(rule); !ok {
+			return fmt.Errorf("invalid limit %q: unknown detector %q", entry, rule)
+		}
+		limits[rule] = limit
+	}
+	return nil
+}
+
+// gate decides whether a run passes. Rules with a limit fail only when they
+// exceed it; every other finding fails the run when its severity is at least
+// failOn. A nil failOn never fails on severity.
+type gate struct {
+	failOn *detectors.Severity
+	limits ruleLimits
+}
+
+// newGate parses the -fail-on value into a gate.
+func newGate(failOn string, limits ruleLimits) (gate, error) {
+	if strings.EqualFold(failOn, failOnNone) {
+		return gate{limits: limits}, nil
+	}
+	severity, err := detectors.ParseSeverity(failOn)
+	if err != nil {
+		return gate{}, err
+	}
+	return gate{failOn: &severity, limits: limits}, nil
+}
+
+// exitCode returns the process exit code for the given results.
+func (g gate) exitCode(results []detectors.Result) int {
+	for _, result := range results {
+		if result.Err != nil {
+			return exitError
+		}
+	}
+
+	counts := make(map[string]int)
+	failed := false
+	for _, result := range results {
+		for _, finding := range result.Findings {
+			if _, limited := g.limits[finding.RuleID]; limited {
+				counts[finding.RuleID]++
+				continue
+			}
+			if g.failOn != nil && finding.Severity >= *g.failOn {
+				failed = true
+			}
+		}
+	}
+	for rule, count := range counts {
+		if count > g.limits[rule] {
+			failed = true
+		}
+	}
+	if failed {
+		return exitFindings
+	}
+	return exitClean
+}
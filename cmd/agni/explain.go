@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Aadi-IRON/agni/config"
+	"github.com/Aadi-IRON/agni/detectors"
+)
+
+// runList implements "agni list".
+func runList(_ []string) int {
+	for _, detector := range detectors.All() {
+		fmt.Printf(config.BoldCyan+"%-24s"+config.Reset+" %-12s %-8s %s\n",
+			detector.Name(), detector.Category(), detector.Severity(), detector.Description())
+	}
+	return exitClean
+}
+
+// runExplain implements "agni explain <rule>".
+func runExplain(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "❌ Usage: agni explain <rule>   (see \"agni list\" for rules)")
+		return exitError
+	}
+	detector, ok := detectors.Lookup(args[0])
+	if !ok {
+		fmt.Fprintf(os.Stderr, "❌ Unknown rule %q (see \"agni list\" for rules)\n", args[0])
+		return exitError
+	}
+
+	fmt.Println(config.CreateCompactBoxHeader(strings.ToUpper(detector.Name()), config.BoldCyan))
+	fmt.Println()
+	fmt.Println(config.BoldYellow + detector.Description() + config.Reset)
+	fmt.Printf("Category: %s    Default severity: %s\n", detector.Category(), detector.Severity())
+	fmt.Println()
+
+	explainer, ok := detector.(detectors.Explainer)
+	if !ok {
+		fmt.Println("No further documentation is available for this rule.")
+		return exitClean
+	}
+	explanation := explainer.Explain()
+	fmt.Println(explanation.Rationale)
+	if explanation.Bad != "" {
+		fmt.Println()
+		fmt.Println(config.BoldRed + "❌ Bad:" + config.Reset)
+		fmt.Println(indent(explanation.Bad))
+	}
+	if explanation.Good != "" {
+		fmt.Println()
+		fmt.Println(config.BoldGreen + "✅ Good:" + config.Reset)
+		fmt.Println(indent(explanation.Good))
+	}
+	return exitClean
+}
+
+// indent prefixes every line of an example with four spaces.
+func indent(example string) string {
+	return "    " + strings.ReplaceAll(example, "\n", "\n    ")
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Aadi-IRON/agni/fix"
+	"github.com/Aadi-IRON/agni/report"
+)
+
+// fixMode is the value of -fix: empty when fixing is off, "write" to apply
+// the fixes and "diff" to print them as a patch instead.
+type fixMode string
+
+func (mode *fixMode) String() string {
+	return string(*mode)
+}
+
+func (mode *fixMode) Set(value string) error {
+	switch value {
+	case "true", "write":
+		*mode = "write"
+	case "diff":
+		*mode = "diff"
+	case "false":
+		*mode = ""
+	default:
+		return fmt.Errorf("unknown fix mode %q (want -fix or -fix=diff)", value)
+	}
+	return nil
+}
+
+// IsBoolFlag lets -fix be given without a value.
+func (mode *fixMode) IsBoolFlag() bool {
+	return true
+}
+
+// applyFixes applies or previews the fixes of the reported findings. It
+// returns false when the fixes could not be planned or written, in which
+// case no file has been changed by the planning step.
+func applyFixes(mode fixMode, run *report.Run) bool {
+	plan, err := fix.New(run.Root, run.Results)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "❌ Error: refusing to fix:", err)
+		return false
+	}
+	if mode == "diff" {
+		if err := plan.Diff(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, "❌ Error writing diff:", err)
+			return false
+		}
+		return true
+	}
+	if err := plan.Write(); err != nil {
+		fmt.Fprintln(os.Stderr, "❌ Error:", err)
+		return false
+	}
+	fmt.Fprintf(os.Stderr, "🛠️  Fixed %d finding(s) in %d file(s)\n", plan.Fixed, plan.Files())
+	plan.Filter(run.Results)
+	return true
+}
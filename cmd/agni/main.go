@@ -1,25 +1,55 @@
 package main
 
 import (
-	"flag"
 	"fmt"
 	"os"
-	"path/filepath"
-
-	"github.com/Aadi-IRON/agni/detectors"
+	"strings"
 )
 
+const usage = `Agni - a modular Go code analyzer
+
+Usage:
+  agni check [flags] [paths...]   run detectors against the given directories (default ".")
+  agni baseline create [paths...] record current findings so that check -baseline ignores them
+  agni list                       list every detector with its description
+  agni explain <rule>             show the rationale and examples of a detector
+  agni version                    print the agni version
+
+Run "agni check -h" to see the flags of the check command.
+`
+
 func main() {
-	// Optional: Allow custom directory via flag
-	dirPtr := flag.String("dir", ".", "Directory to run Agni checks in")
-	flag.Parse()
+	os.Exit(run(os.Args[1:]))
+}
 
-	absPath, err := filepath.Abs(*dirPtr)
-	if err != nil {
-		fmt.Println("❌ Error getting absolute path:", err)
-		os.Exit(1)
+// run dispatches to a subcommand and returns the process exit code.
+func run(args []string) int {
+	// Without a subcommand, agni behaves like "agni check" so that the
+	// original "agni -dir path" invocation keeps working.
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		if len(args) > 0 && (args[0] == "-h" || args[0] == "-help" || args[0] == "--help") {
+			fmt.Print(usage)
+			return exitClean
+		}
+		return runCheck(args)
 	}
 
-	fmt.Println("🔥 Running Agni checks in:", absPath)
-	detectors.RunAll(absPath)
+	command, rest := args[0], args[1:]
+	switch command {
+	case "check":
+		return runCheck(rest)
+	case "baseline":
+		return runBaseline(rest)
+	case "list":
+		return runList(rest)
+	case "explain":
+		return runExplain(rest)
+	case "version":
+		return runVersion(rest)
+	case "help":
+		fmt.Print(usage)
+		return exitClean
+	}
+	fmt.Fprintf(os.Stderr, "❌ Unknown command %q\n\n%s", command, usage)
+	return exitError
 }
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// runVersion implements "agni version".
+func runVersion(_ []string) int {
+	fmt.Println("agni", version())
+	return exitClean
+}
+
+// version returns the module version from the build info, falling back to the
+// VCS revision for local builds.
+func version() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown (" + runtime.Version() + ")"
+	}
+	version := info.Main.Version
+	var revision, modified string
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			if setting.Value == "true" {
+				modified = "-dirty"
+			}
+		}
+	}
+	if (version == "" || version == "(devel)") && revision != "" {
+		if len(revision) > 12 {
+			revision = revision[:12]
+		}
+		version = "(devel) " + revision + modified
+	}
+	return fmt.Sprintf("%s (%s)", version, info.GoVersion)
+}
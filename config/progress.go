@@ -2,12 +2,20 @@ package config
 
 import (
 	"fmt"
+	"os"
 	"strings"
+	"sync"
 	"time"
 )
 
-// ProgressBar represents a simple progress bar
+// terminal serializes writes of the progress indicators, which may be driven
+// from several goroutines when detectors run in parallel.
+var terminal sync.Mutex
+
+// ProgressBar represents a simple progress bar. Like the other progress
+// indicators it writes to stderr so it never mixes with reports on stdout.
 type ProgressBar struct {
+	mutex       sync.Mutex
 	total       int
 	current     int
 	width       int
@@ -35,6 +43,8 @@ func NewProgressBar(total int, description string) *ProgressBar {
 
 // Update updates the progress bar
 func (bar *ProgressBar) Update(current int) {
+	bar.mutex.Lock()
+	defer bar.mutex.Unlock()
 	bar.current = current
 	bar.lastUpdate = time.Now()
 	bar.render()
@@ -42,16 +52,22 @@ func (bar *ProgressBar) Update(current int) {
 
 // Increment increments the progress by 1
 func (bar *ProgressBar) Increment() {
-	bar.Update(bar.current + 1)
+	bar.mutex.Lock()
+	defer bar.mutex.Unlock()
+	bar.current++
+	bar.lastUpdate = time.Now()
+	bar.render()
 }
 
 // SetTotal updates the total count
 func (bar *ProgressBar) SetTotal(total int) {
+	bar.mutex.Lock()
+	defer bar.mutex.Unlock()
 	bar.total = total
 	bar.render()
 }
 
-// render renders the progress bar
+// render renders the progress bar. The caller must hold bar.mutex.
 func (bar *ProgressBar) render() {
 	if bar.total <= 0 {
 		return
@@ -85,13 +101,20 @@ func (bar *ProgressBar) render() {
 		bar.total,
 		formatDuration(remaining)+Reset)
 
-	fmt.Print(output)
+	terminal.Lock()
+	fmt.Fprint(os.Stderr, output)
+	terminal.Unlock()
 }
 
 // Finish completes the progress bar
 func (bar *ProgressBar) Finish() {
-	bar.Update(bar.total)
-	fmt.Println() // Move to next line
+	bar.mutex.Lock()
+	defer bar.mutex.Unlock()
+	bar.current = bar.total
+	bar.render()
+	terminal.Lock()
+	fmt.Fprintln(os.Stderr) // Move to next line
+	terminal.Unlock()
 }
 
 // formatDuration formats duration in a human-readable way
@@ -110,6 +133,7 @@ func formatDuration(d time.Duration) string {
 
 // SimpleProgressBar creates a simpler progress indicator
 type SimpleProgressBar struct {
+	mutex       sync.Mutex
 	total       int
 	current     int
 	description string
@@ -126,28 +150,46 @@ func NewSimpleProgressBar(total int, description string) *SimpleProgressBar {
 
 // Update updates the simple progress bar
 func (spb *SimpleProgressBar) Update(current int) {
+	spb.mutex.Lock()
+	defer spb.mutex.Unlock()
 	spb.current = current
-	if spb.total > 0 {
-		percentage := float64(spb.current) / float64(spb.total) * 100
-		fmt.Printf("\r%s %s%3.1f%% (%d/%d)%s",
-			BoldCyan+spb.description+Reset,
-			BoldYellow,
-			percentage,
-			spb.current,
-			spb.total,
-			Reset)
-	}
+	spb.render()
 }
 
 // Increment increments the progress by 1
 func (spb *SimpleProgressBar) Increment() {
-	spb.Update(spb.current + 1)
+	spb.mutex.Lock()
+	defer spb.mutex.Unlock()
+	spb.current++
+	spb.render()
 }
 
 // Finish completes the simple progress bar
 func (spb *SimpleProgressBar) Finish() {
-	spb.Update(spb.total)
-	fmt.Println()
+	spb.mutex.Lock()
+	defer spb.mutex.Unlock()
+	spb.current = spb.total
+	spb.render()
+	terminal.Lock()
+	fmt.Fprintln(os.Stderr)
+	terminal.Unlock()
+}
+
+// render renders the simple progress bar. The caller must hold spb.mutex.
+func (spb *SimpleProgressBar) render() {
+	if spb.total <= 0 {
+		return
+	}
+	percentage := float64(spb.current) / float64(spb.total) * 100
+	terminal.Lock()
+	fmt.Fprintf(os.Stderr, "\r%s %s%3.1f%% (%d/%d)%s",
+		BoldCyan+spb.description+Reset,
+		BoldYellow,
+		percentage,
+		spb.current,
+		spb.total,
+		Reset)
+	terminal.Unlock()
 }
 
 // Spinner creates a simple spinning indicator
@@ -156,6 +198,9 @@ type Spinner struct {
 	stopChan    chan bool
 	spinner     []string
 	index       int
+	startOnce   sync.Once
+	stopOnce    sync.Once
+	done        chan struct{}
 }
 
 // NewSpinner creates a new spinner
@@ -163,34 +208,50 @@ func NewSpinner(description string) *Spinner {
 	return &Spinner{
 		description: description,
 		stopChan:    make(chan bool),
+		done:        make(chan struct{}),
 		spinner:     []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"},
 		index:       0,
 	}
 }
 
-// Start starts the spinner
+// Start starts the spinner. Calling it more than once has no effect.
 func (sp *Spinner) Start() {
-	go func() {
-		ticker := time.NewTicker(100 * time.Millisecond)
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-ticker.C:
-				fmt.Printf("\r%s %s %s",
-					BoldCyan+sp.description+Reset,
-					BoldYellow+sp.spinner[sp.index]+Reset,
-					Reset)
-				sp.index = (sp.index + 1) % len(sp.spinner)
-			case <-sp.stopChan:
-				return
+	sp.startOnce.Do(func() {
+		go func() {
+			defer close(sp.done)
+			ticker := time.NewTicker(100 * time.Millisecond)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ticker.C:
+					terminal.Lock()
+					fmt.Fprintf(os.Stderr, "\r%s %s %s",
+						BoldCyan+sp.description+Reset,
+						BoldYellow+sp.spinner[sp.index]+Reset,
+						Reset)
+					terminal.Unlock()
+					sp.index = (sp.index + 1) % len(sp.spinner)
+				case <-sp.stopChan:
+					return
+				}
 			}
-		}
-	}()
+		}()
+	})
 }
 
-// Stop stops the spinner
+// Stop stops the spinner and waits for it to finish drawing. It is safe to
+// call Stop more than once, or on a spinner that was never started.
 func (sp *Spinner) Stop() {
-	sp.stopChan <- true
-	fmt.Println()
+	sp.stopOnce.Do(func() {
+		started := true
+		sp.startOnce.Do(func() { started = false })
+		if started {
+			close(sp.stopChan)
+			<-sp.done
+		}
+		terminal.Lock()
+		fmt.Fprintln(os.Stderr)
+		terminal.Unlock()
+	})
 }
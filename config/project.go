@@ -0,0 +1,109 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectFileNames are the configuration file names looked up, in order, in
+// the scan root and each of its parents.
+var ProjectFileNames = []string{".agni.yaml", ".agni.yml", ".agni.json"}
+
+// Project is the per-project configuration read from an .agni.yaml or
+// .agni.json file.
+type Project struct {
+	// Path is the file the configuration was loaded from; empty for defaults.
+	Path      string                    `yaml:"-" json:"-"`
+	Detectors map[string]DetectorConfig `yaml:"detectors" json:"detectors"`
+}
+
+// DetectorConfig holds the settings of a single detector.
+type DetectorConfig struct {
+	// Enabled turns the detector on or off; nil keeps it enabled.
+	Enabled *bool `yaml:"enabled" json:"enabled"`
+	// Severity overrides the default severity of the detector's findings.
+	Severity string `yaml:"severity" json:"severity"`
+	// Options are detector-specific settings.
+	Options map[string]any `yaml:"options" json:"options"`
+}
+
+// IsEnabled reports whether the named detector is enabled.
+func (project *Project) IsEnabled(name string) bool {
+	if project == nil {
+		return true
+	}
+	settings, ok := project.Detectors[name]
+	return !ok || settings.Enabled == nil || *settings.Enabled
+}
+
+// Detector returns the settings of the named detector.
+func (project *Project) Detector(name string) DetectorConfig {
+	if project == nil {
+		return DetectorConfig{}
+	}
+	return project.Detectors[name]
+}
+
+// FindProjectFile looks for a configuration file in dir and its parents and
+// returns its path, or "" when there is none.
+func FindProjectFile(dir string) string {
+	for {
+		for _, name := range ProjectFileNames {
+			candidate := filepath.Join(dir, name)
+			if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+				return candidate
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// LoadProject discovers and loads the configuration for the given scan root.
+// When no configuration file exists, an empty configuration is returned.
+func LoadProject(root string) (*Project, error) {
+	path := FindProjectFile(root)
+	if path == "" {
+		return &Project{}, nil
+	}
+	return LoadProjectFile(path)
+}
+
+// LoadProjectFile loads the configuration from the given file. Files ending in
+// .json are read as JSON, anything else as YAML.
+func LoadProjectFile(path string) (*Project, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config %s: %v", path, err)
+	}
+	project := &Project{}
+	if strings.HasSuffix(path, ".json") {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(project)
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(project)
+		if errors.Is(err, io.EOF) {
+			// An empty YAML file is a valid, empty configuration
+			err = nil
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing config %s: %v", path, err)
+	}
+	project.Path = path
+	return project, nil
+}
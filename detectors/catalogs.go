@@ -0,0 +1,250 @@
+package detectors
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// defaultCatalogPattern matches the names of the message maps found
+// without configuration: Messages, SuccessMessages, FailMessages, Message…
+// optionally followed by a locale, as in MessagesFR or Messages_pt_BR.
+const defaultCatalogPattern = `Messages?(?:[A-Z][A-Za-z]|_[a-z]{2,3}(?:_[A-Za-z]{2,4})?)?$`
+
+// catalogSpec names the message maps of one package.
+type catalogSpec struct {
+	// Package is an import path, or a directory relative to the root such
+	// as "./i18n".
+	Package string `json:"package"`
+	// Vars are the names of the package-level map variables.
+	Vars []string `json:"vars"`
+}
+
+// catalogOptions locate the message catalogs. Both message detectors
+// accept them.
+type catalogOptions struct {
+	// Catalogs are declared explicitly and must exist.
+	Catalogs []catalogSpec `json:"catalogs"`
+	// Pattern is a regular expression; every package-level map[string]string
+	// variable whose name matches it is a catalog too. An empty pattern turns
+	// discovery off.
+	Pattern *string `json:"pattern"`
+	// Maps are extra variable names discovered like the pattern.
+	Maps []string `json:"maps"`
+	// Files limit discovery to these files, given by base name or by path
+	// relative to the root.
+	Files []string `json:"files"`
+}
+
+// MessageCatalog is a package-level map from message keys to messages.
+type MessageCatalog struct {
+	// Package is the import path of the declaring package.
+	Package string
+	Name    string
+	File    *File
+	Keys    []MessageKey
+
+	literal *ast.CompositeLit
+	// object is nil when the declaring file was not type-checked.
+	object types.Object
+}
+
+// MessageKey is a key defined in a message map.
+type MessageKey struct {
+	Key string
+	// Value is the message, when it is a constant.
+	Value    string
+	Position token.Position
+
+	valueKnown bool
+}
+
+// String returns the qualified name of the catalog, such as "i18n.Messages".
+func (catalog *MessageCatalog) String() string {
+	return catalog.File.AST.Name.Name + "." + catalog.Name
+}
+
+// findCatalogs returns the configured and discovered message catalogs of
+// the program, in file order.
+func findCatalogs(program *Program, options catalogOptions) ([]*MessageCatalog, error) {
+	pattern := defaultCatalogPattern
+	if options.Pattern != nil {
+		pattern = *options.Pattern
+	}
+	var discover *regexp.Regexp
+	if pattern != "" {
+		var err error
+		if discover, err = regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("invalid options: pattern: %v", err)
+		}
+	}
+	extra := make(map[string]bool)
+	for _, name := range options.Maps {
+		extra[name] = true
+	}
+
+	info := program.TypesInfo()
+	var catalogs []*MessageCatalog
+	// found records the configured catalogs, as "package.Var"
+	found := make(map[string]bool)
+	for _, file := range program.Files {
+		if file.AST == nil || file.IsTest {
+			continue
+		}
+		for _, decl := range file.AST.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				valueSpec := spec.(*ast.ValueSpec)
+				for idx, name := range valueSpec.Names {
+					if idx >= len(valueSpec.Values) {
+						break
+					}
+					literal, ok := valueSpec.Values[idx].(*ast.CompositeLit)
+					if !ok || !isStringMap(file, info, name, valueSpec.Type, literal) {
+						continue
+					}
+					configured := configuredCatalog(program, file, name.Name, options.Catalogs)
+					if configured != nil {
+						found[configured.Package+"."+name.Name] = true
+					} else if !extra[name.Name] && (discover == nil || !discover.MatchString(name.Name) || !inFiles(program.Root, file.Path, options.Files)) {
+						continue
+					}
+					catalog := &MessageCatalog{
+						Package: file.Package.Path,
+						Name:    name.Name,
+						File:    file,
+						literal: literal,
+						object:  info.Defs[name],
+					}
+					catalog.Keys = literalKeys(program, file, info, literal)
+					catalogs = append(catalogs, catalog)
+				}
+			}
+		}
+	}
+
+	for _, spec := range options.Catalogs {
+		for _, name := range spec.Vars {
+			if !found[spec.Package+"."+name] {
+				return catalogs, fmt.Errorf("message catalog %s.%s not found", spec.Package, name)
+			}
+		}
+	}
+	return catalogs, nil
+}
+
+// configuredCatalog returns the catalog spec declaring the variable name of
+// file, or nil.
+func configuredCatalog(program *Program, file *File, name string, specs []catalogSpec) *catalogSpec {
+	for idx, spec := range specs {
+		if !catalogPackageMatches(program, file, spec.Package) {
+			continue
+		}
+		for _, candidate := range spec.Vars {
+			if candidate == name {
+				return &specs[idx]
+			}
+		}
+	}
+	return nil
+}
+
+// catalogPackageMatches reports whether file belongs to the package given by
+// import path or by directory relative to the root.
+func catalogPackageMatches(program *Program, file *File, pkg string) bool {
+	if file.Package != nil && file.Package.Path == pkg {
+		return true
+	}
+	if !strings.HasPrefix(pkg, ".") {
+		return false
+	}
+	return filepath.Join(program.Root, filepath.FromSlash(pkg)) == filepath.Dir(file.Path)
+}
+
+// inFiles reports whether path is one of files, given by base name or by
+// path relative to root. An empty list matches every path.
+func inFiles(root, path string, files []string) bool {
+	if len(files) == 0 {
+		return true
+	}
+	for _, name := range files {
+		if strings.EqualFold(filepath.Base(path), name) || filepath.Join(root, filepath.FromSlash(name)) == path {
+			return true
+		}
+	}
+	return false
+}
+
+// isStringMap reports whether the variable holds a map from strings to
+// strings. Without type information the declared or literal type must be
+// spelled map[string]string.
+func isStringMap(file *File, info *types.Info, name *ast.Ident, declared ast.Expr, literal *ast.CompositeLit) bool {
+	if file.Checked {
+		object := info.Defs[name]
+		if object == nil {
+			return false
+		}
+		mapType, ok := object.Type().Underlying().(*types.Map)
+		if !ok {
+			return false
+		}
+		key, keyOK := mapType.Key().Underlying().(*types.Basic)
+		elem, elemOK := mapType.Elem().Underlying().(*types.Basic)
+		return keyOK && elemOK && key.Kind() == types.String && elem.Kind() == types.String
+	}
+	typ := declared
+	if typ == nil {
+		typ = literal.Type
+	}
+	mapType, ok := typ.(*ast.MapType)
+	if !ok {
+		return false
+	}
+	key, keyOK := mapType.Key.(*ast.Ident)
+	value, valueOK := mapType.Value.(*ast.Ident)
+	return keyOK && valueOK && key.Name == "string" && value.Name == "string"
+}
+
+// literalKeys returns the keys of a map literal: string literals, and
+// constants when the file was type-checked.
+func literalKeys(program *Program, file *File, info *types.Info, literal *ast.CompositeLit) []MessageKey {
+	var keys []MessageKey
+	for _, elt := range literal.Elts {
+		keyValue, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		if key, ok := stringValue(file, info, keyValue.Key); ok {
+			messageKey := MessageKey{Key: key, Position: program.Position(keyValue.Key.Pos())}
+			messageKey.Value, messageKey.valueKnown = stringValue(file, info, keyValue.Value)
+			keys = append(keys, messageKey)
+		}
+	}
+	return keys
+}
+
+// stringValue returns the value of a constant string expression: a string
+// literal, or any constant expression in a type-checked file.
+func stringValue(file *File, info *types.Info, expr ast.Expr) (string, bool) {
+	if file.Checked {
+		if value := info.Types[expr].Value; value != nil && value.Kind() == constant.String {
+			return constant.StringVal(value), true
+		}
+		return "", false
+	}
+	literal, ok := expr.(*ast.BasicLit)
+	if !ok || literal.Kind != token.STRING {
+		return "", false
+	}
+	value, err := strconv.Unquote(literal.Value)
+	return value, err == nil
+}
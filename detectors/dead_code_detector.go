@@ -2,63 +2,340 @@ package detectors
 
 import (
 	"fmt"
-	"os"
-	"os/exec"
-
-	"github.com/Aadi-IRON/agni/config"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"regexp"
+	"strings"
 )
 
-// RunDeadCode checks if dead code is installed; if not, it installs it, then runs it
-func RunDeadCode(path string) {
-	fmt.Println(config.CreateCompactBoxHeader("DEAD CODE", config.BoldPurple))
-	fmt.Println()
-	fmt.Println(config.Purple + "🔍 Scanning for dead code... Time to clean the skeletons from your closet 🧹")
+func init() {
+	Register(Document(
+		NewDetector(
+			"dead-code",
+			"Reports functions that are unreachable from main packages and tests",
+			CategoryUnused,
+			SeverityWarning,
+			DetectDeadCode,
+		),
+		Explanation{
+			Rationale: `Functions that cannot be reached from any main package or test are dead
+weight: they must still compile, be reviewed and be kept up to date. Remove
+them. Functions reached only through reflection, plugins or code generation
+should be listed under the detector's entry-points option instead.`,
+			Bad:  `func legacyExport() { ... } // no caller anywhere`,
+			Good: `// legacyExport removed`,
+		},
+	))
+}
 
-	// Check if 'deadcode' is available in PATH
-	_, err := exec.LookPath("deadcode")
-	if err != nil {
-		fmt.Println("⚠️  'deadcode' not found. Attempting to install it...")
+// deadCodeOptions are the settings of the dead-code detector.
+type deadCodeOptions struct {
+	// EntryPoints are extra roots, written as "import/path.Func" or
+	// "import/path.Type.Method"; '*' matches any run of characters.
+	EntryPoints []string `json:"entry-points"`
+	// Tests makes test, benchmark, fuzz and example functions roots.
+	Tests bool `json:"tests"`
+}
 
-		// Create spinner for installation
-		spinner := config.NewSpinner("📦 Installing deadcode tool")
-		spinner.Start()
+// Detects functions and methods that no main package, test or configured
+// entry point can reach. The call graph is built from the type-checked
+// program: every reference to a function counts as an edge, so calls,
+// function values and method values are all followed. Interface dispatch is
+// handled conservatively: once a type is in use, its exported methods and
+// every method whose name is called through an interface are reachable.
+func DetectDeadCode(pass *Pass) ([]Finding, error) {
+	options := deadCodeOptions{Tests: true}
+	if err := pass.DecodeOptions(&options); err != nil {
+		return nil, err
+	}
+	var entryPoints []*regexp.Regexp
+	for _, pattern := range options.EntryPoints {
+		expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$"
+		entryPoints = append(entryPoints, regexp.MustCompile(expr))
+	}
 
-		// Attempt to install it using `go install`
-		installCmd := exec.Command("go", "install", "golang.org/x/tools/cmd/deadcode@latest")
-		installCmd.Env = os.Environ() // inherit user's env
-		output, installErr := installCmd.CombinedOutput()
+	graph := newReachability(pass.Program)
+	if !graph.addRoots(options.Tests, entryPoints) {
+		// Without a main package, test or entry point everything would be dead
+		return nil, nil
+	}
+	graph.solve()
 
-		spinner.Stop()
+	var findings []Finding
+	for _, function := range graph.declared {
+		if graph.reachable[function.object] || function.file.IsTest {
+			continue
+		}
+		name := function.decl.Name
+		finding := NewFinding(pass.Program.Position(name.Pos()), pass.Program.Position(name.End()),
+			fmt.Sprintf("unreachable func: %s", funcName(function.object)))
+		finding.SuggestedFix = "Remove the function, or list it under the dead-code entry-points option if it is reached through reflection"
+		findings = append(findings, finding)
+	}
+	return findings, nil
+}
+
+// declaredFunc is a function or method declared in the analyzed module.
+type declaredFunc struct {
+	object *types.Func
+	decl   *ast.FuncDecl
+	file   *File
+}
+
+// reachability computes the functions reachable from a set of roots.
+type reachability struct {
+	program  *Program
+	info     *types.Info
+	declared []*declaredFunc
+	funcs    map[types.Object]*declaredFunc
+	// typeSpecs and methods describe the module's named types.
+	typeSpecs map[types.Object]*ast.TypeSpec
+	methods   map[types.Object][]*types.Func
+	// byName serves files that could not be type-checked.
+	byName map[string][]types.Object
+
+	reachable map[types.Object]bool
+	live      map[types.Object]bool
+	dynamic   map[string]bool
+	queue     []ast.Node
+}
+
+func newReachability(program *Program) *reachability {
+	graph := &reachability{
+		program:   program,
+		info:      program.TypesInfo(),
+		funcs:     make(map[types.Object]*declaredFunc),
+		typeSpecs: make(map[types.Object]*ast.TypeSpec),
+		methods:   make(map[types.Object][]*types.Func),
+		byName:    make(map[string][]types.Object),
+		reachable: make(map[types.Object]bool),
+		live:      make(map[types.Object]bool),
+		dynamic:   make(map[string]bool),
+	}
+	for _, file := range program.Files {
+		if !file.Checked {
+			continue
+		}
+		for _, decl := range file.AST.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				object, ok := graph.info.Defs[decl.Name].(*types.Func)
+				if !ok || decl.Name.Name == "_" {
+					continue
+				}
+				function := &declaredFunc{object: object, decl: decl, file: file}
+				graph.declared = append(graph.declared, function)
+				graph.funcs[object] = function
+				graph.byName[object.Name()] = append(graph.byName[object.Name()], object)
+				if receiver := receiverTypeName(object); receiver != nil {
+					graph.methods[receiver] = append(graph.methods[receiver], object)
+				}
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					if typeSpec, ok := spec.(*ast.TypeSpec); ok {
+						if object := graph.info.Defs[typeSpec.Name]; object != nil {
+							graph.typeSpecs[object] = typeSpec
+							graph.byName[object.Name()] = append(graph.byName[object.Name()], object)
+						}
+					}
+				}
+			}
+		}
+	}
+	return graph
+}
+
+// addRoots marks the program's entry points reachable and reports whether
+// there was any.
+func (graph *reachability) addRoots(tests bool, entryPoints []*regexp.Regexp) bool {
+	found := false
+	for _, function := range graph.declared {
+		if function.file.IsTest && !tests {
+			continue
+		}
+		name := function.decl.Name.Name
+		isRoot := false
+		switch {
+		case function.decl.Recv == nil && name == "init":
+			// init functions run whenever their package is linked in
+			graph.markFunc(function.object)
+		case function.file.IsTest:
+			isRoot = function.decl.Recv == nil && isTestEntry(name)
+		case function.decl.Recv == nil && name == "main" && function.file.AST.Name.Name == "main":
+			isRoot = true
+		default:
+			isRoot = hasExportDirective(function.decl) || matchesAny(entryPoints, qualifiedName(function.object))
+		}
+		if isRoot {
+			found = true
+			graph.markFunc(function.object)
+		}
+	}
+
+	for _, file := range graph.program.Files {
+		if file.AST == nil || (file.IsTest && !tests) {
+			continue
+		}
+		if !file.Checked {
+			// Nothing is resolved in files excluded from type checking, so
+			// whatever they mention by name is kept alive
+			ast.Inspect(file.AST, func(astNode ast.Node) bool {
+				if ident, ok := astNode.(*ast.Ident); ok {
+					for _, object := range graph.byName[ident.Name] {
+						graph.markObject(object)
+					}
+				}
+				return true
+			})
+			continue
+		}
+		// Package-level variable initializers run at program start
+		for _, decl := range file.AST.Decls {
+			if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.VAR {
+				graph.queue = append(graph.queue, genDecl)
+			}
+		}
+	}
+	return found
+}
+
+// solve walks the queued declarations until no new function or type is found.
+func (graph *reachability) solve() {
+	for len(graph.queue) > 0 {
+		node := graph.queue[len(graph.queue)-1]
+		graph.queue = graph.queue[:len(graph.queue)-1]
+		ast.Inspect(node, func(astNode ast.Node) bool {
+			if ident, ok := astNode.(*ast.Ident); ok {
+				if object := graph.info.Uses[ident]; object != nil {
+					graph.markObject(object)
+				}
+			}
+			return true
+		})
+	}
+}
 
-		if installErr != nil {
-			fmt.Println(config.Red+"❌ Failed to install 'deadcode':", string(output))
+// markObject records a reference to a function or a type.
+func (graph *reachability) markObject(object types.Object) {
+	switch object := object.(type) {
+	case *types.Func:
+		if signature, ok := object.Type().(*types.Signature); ok && signature.Recv() != nil && types.IsInterface(signature.Recv().Type()) {
+			graph.markDynamic(object.Name())
 			return
 		}
-		fmt.Println(config.Green + "✅ 'deadcode' installed successfully.")
+		graph.markFunc(object)
+	case *types.TypeName:
+		graph.markType(object)
 	}
+}
 
-	// Create spinner for analysis
-	spinner := config.NewSpinner("🧠 Analyzing code for dead functions")
-	spinner.Start()
+// markFunc makes a declared function reachable and queues its declaration.
+func (graph *reachability) markFunc(object *types.Func) {
+	object = object.Origin()
+	function, declared := graph.funcs[object]
+	if !declared || graph.reachable[object] {
+		return
+	}
+	graph.reachable[object] = true
+	graph.queue = append(graph.queue, function.decl)
+}
 
-	// Now run deadcode on the provided path
-	cmd := exec.Command("deadcode", path)
-	output, err := cmd.CombinedOutput()
+// markType makes a named type live: values of it may exist, so its
+// exported methods and the methods called through interfaces are reachable.
+func (graph *reachability) markType(object *types.TypeName) {
+	if named, ok := object.Type().(*types.Named); ok {
+		object = named.Origin().Obj()
+	}
+	typeSpec, declared := graph.typeSpecs[object]
+	if !declared || graph.live[object] {
+		return
+	}
+	graph.live[object] = true
+	graph.queue = append(graph.queue, typeSpec)
+	for _, method := range graph.methods[object] {
+		if method.Exported() || graph.dynamic[method.Name()] {
+			graph.markFunc(method)
+		}
+	}
+}
 
-	spinner.Stop()
+// markDynamic records a call through an interface method.
+func (graph *reachability) markDynamic(name string) {
+	if graph.dynamic[name] {
+		return
+	}
+	graph.dynamic[name] = true
+	for receiver := range graph.live {
+		for _, method := range graph.methods[receiver] {
+			if method.Name() == name {
+				graph.markFunc(method)
+			}
+		}
+	}
+}
 
-	if err != nil {
-		fmt.Println("❌ Error running 'deadcode':", err)
+// receiverTypeName returns the named type a method is declared on.
+func receiverTypeName(function *types.Func) types.Object {
+	signature, ok := function.Type().(*types.Signature)
+	if !ok || signature.Recv() == nil {
+		return nil
 	}
-	if len(output) == 0 {
-		fmt.Println(config.BoldGreen + "✅ No dead code found.")
-	} else {
-		fmt.Println()
-		fmt.Println(config.BoldYellow + "🧠 Deadcode report:")
-		fmt.Println()
-		fmt.Println(config.Red + string(output))
-		fmt.Println()
-		fmt.Println(config.Purple + "⚠️  Note: Some functions may be falsely flagged as unused.")
-		fmt.Println(config.Purple + ">>> Always cross-check before deletion to avoid accidental removal of valid code.")
+	receiver := signature.Recv().Type()
+	if pointer, ok := receiver.(*types.Pointer); ok {
+		receiver = pointer.Elem()
+	}
+	if named, ok := receiver.(*types.Named); ok {
+		return named.Origin().Obj()
+	}
+	return nil
+}
+
+// isTestEntry reports whether a test-file function is run by go test.
+func isTestEntry(name string) bool {
+	for _, prefix := range []string{"Test", "Benchmark", "Fuzz", "Example"} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasExportDirective reports whether the function is exported to C or
+// linked by name, which makes it reachable from outside Go code.
+func hasExportDirective(function *ast.FuncDecl) bool {
+	if function.Doc == nil {
+		return false
+	}
+	for _, comment := range function.Doc.List {
+		if strings.HasPrefix(comment.Text, "//export ") || strings.HasPrefix(comment.Text, "//go:linkname ") {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAny(patterns []*regexp.Regexp, name string) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// qualifiedName returns "import/path.Func" or "import/path.Type.Method".
+func qualifiedName(function *types.Func) string {
+	if function.Pkg() == nil {
+		return funcName(function)
+	}
+	return function.Pkg().Path() + "." + funcName(function)
+}
+
+// funcName returns "Func" or "Type.Method".
+func funcName(function *types.Func) string {
+	if receiver := receiverTypeName(function); receiver != nil {
+		return receiver.Name() + "." + function.Name()
 	}
+	return function.Name()
 }
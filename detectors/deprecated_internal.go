@@ -0,0 +1,195 @@
+package detectors
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+)
+
+func init() {
+	Register(Document(
+		NewDetector(
+			"deprecated-internal",
+			"Reports uses of the module's own declarations marked // Deprecated:",
+			CategoryDeprecation,
+			SeverityWarning,
+			DetectDeprecatedInternal,
+		),
+		Explanation{
+			Rationale: `A "Deprecated:" paragraph in a doc comment announces that a helper is on its
+way out, but nothing makes callers move. Every use outside the declaring file
+is reported, and each message carries the number of uses left, so the
+migration can be tracked until the declaration can be deleted. A use silenced
+with an ignore directive is not counted.`,
+			Bad: `// Deprecated: use FetchUser instead.
+func GetUser(id int) User { ... }
+
+user := store.GetUser(id)`,
+			Good: `user := store.FetchUser(ctx, id)`,
+		},
+	))
+}
+
+// internalDeprecation is a deprecated declaration of the analyzed module.
+type internalDeprecation struct {
+	// Name is qualified with the package name: "store.GetUser" or "store.User.Name".
+	Name   string
+	Notice string
+	File   *File
+	uses   []*ast.Ident
+	files  map[*File]bool
+}
+
+// Detects uses of deprecated functions, methods, types, constants,
+// variables and fields declared in the module. Uses in test files, in the
+// declaring file and inside other deprecated declarations are not counted:
+// they go away together with the declaration. Neither are uses suppressed
+// with a directive, so the counts only cover the uses left to migrate.
+func DetectDeprecatedInternal(pass *Pass) ([]Finding, error) {
+	program := pass.Program
+	info := program.TypesInfo()
+
+	deprecated := make(map[types.Object]*internalDeprecation)
+	for _, file := range program.Files {
+		if !file.Checked || file.IsTest {
+			continue
+		}
+		indexDeprecations(file, info, deprecated)
+	}
+	if len(deprecated) == 0 {
+		return nil, nil
+	}
+
+	for _, file := range program.Files {
+		if !file.Checked || file.IsTest {
+			continue
+		}
+		for _, decl := range file.AST.Decls {
+			if declIsDeprecated(decl, info, deprecated) {
+				continue
+			}
+			ast.Inspect(decl, func(astNode ast.Node) bool {
+				ident, ok := astNode.(*ast.Ident)
+				if !ok {
+					return true
+				}
+				object := info.Uses[ident]
+				if function, isFunc := object.(*types.Func); isFunc {
+					object = function.Origin()
+				} else if variable, isVar := object.(*types.Var); isVar {
+					object = variable.Origin()
+				}
+				symbol, found := deprecated[object]
+				if !found || symbol.File == file {
+					return true
+				}
+				if pass.Suppressed(program.Position(ident.Pos())) {
+					return true
+				}
+				symbol.uses = append(symbol.uses, ident)
+				symbol.files[file] = true
+				return true
+			})
+		}
+	}
+
+	var findings []Finding
+	for _, symbol := range deprecated {
+		for _, ident := range symbol.uses {
+			finding := NewFinding(program.Position(ident.Pos()), program.Position(ident.End()),
+				fmt.Sprintf("'%s' is deprecated and still used %d time(s) in %d file(s)", symbol.Name, len(symbol.uses), len(symbol.files)))
+			finding.SuggestedFix = symbol.Notice
+			findings = append(findings, finding)
+		}
+	}
+	return findings, nil
+}
+
+// indexDeprecations records the declarations of file whose doc comment has
+// a "Deprecated:" paragraph. A comment on a grouped declaration covers every
+// spec of the group.
+func indexDeprecations(file *File, info *types.Info, deprecated map[types.Object]*internalDeprecation) {
+	pkgName := file.AST.Name.Name
+	add := func(ident *ast.Ident, name string, docs ...*ast.CommentGroup) {
+		object := info.Defs[ident]
+		if object == nil {
+			return
+		}
+		for _, doc := range docs {
+			if notice := deprecationNotice(doc); notice != "" {
+				deprecated[object] = &internalDeprecation{
+					Name:   pkgName + "." + name,
+					Notice: notice,
+					File:   file,
+					files:  make(map[*File]bool),
+				}
+				return
+			}
+		}
+	}
+
+	for _, decl := range file.AST.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			name := decl.Name.Name
+			if decl.Recv != nil && len(decl.Recv.List) > 0 {
+				name = receiverName(decl.Recv.List[0].Type) + "." + name
+			}
+			add(decl.Name, name, decl.Doc)
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					add(spec.Name, spec.Name.Name, spec.Doc, decl.Doc)
+					var members *ast.FieldList
+					switch typ := spec.Type.(type) {
+					case *ast.StructType:
+						members = typ.Fields
+					case *ast.InterfaceType:
+						members = typ.Methods
+					}
+					if members == nil {
+						continue
+					}
+					for _, member := range members.List {
+						for _, name := range member.Names {
+							add(name, spec.Name.Name+"."+name.Name, member.Doc, member.Comment)
+						}
+					}
+				case *ast.ValueSpec:
+					for _, name := range spec.Names {
+						add(name, name.Name, spec.Doc, spec.Comment, decl.Doc)
+					}
+				}
+			}
+		}
+	}
+}
+
+// declIsDeprecated reports whether a top-level declaration only declares
+// deprecated objects.
+func declIsDeprecated(decl ast.Decl, info *types.Info, deprecated map[types.Object]*internalDeprecation) bool {
+	switch decl := decl.(type) {
+	case *ast.FuncDecl:
+		return deprecated[info.Defs[decl.Name]] != nil
+	case *ast.GenDecl:
+		for _, spec := range decl.Specs {
+			switch spec := spec.(type) {
+			case *ast.TypeSpec:
+				if deprecated[info.Defs[spec.Name]] == nil {
+					return false
+				}
+			case *ast.ValueSpec:
+				for _, name := range spec.Names {
+					if deprecated[info.Defs[name]] == nil {
+						return false
+					}
+				}
+			default:
+				return false
+			}
+		}
+		return len(decl.Specs) > 0
+	}
+	return false
+}
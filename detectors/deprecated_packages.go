@@ -2,21 +2,47 @@ package detectors
 
 import (
 	"fmt"
-	"go/parser"
-	"go/token"
-	"os"
+	"path"
 	"path/filepath"
 	"strings"
-
-	"github.com/Aadi-IRON/agni/config"
 )
 
 // DeprecatedPackage represents a deprecated package with its details
 type DeprecatedPackage struct {
-	Name        string
-	Description string
-	Alternative string
-	Since       string
+	// Name is an import path, or a path ending in "/..." that also matches
+	// every package below it.
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Alternative string `json:"alternative"`
+	Since       string `json:"since"`
+	// Versions restricts the entry to the versions of the providing module
+	// that go.mod requires, such as "<1.4.2" or ">=1.0.0, <2.0.0 || 3.0.0".
+	// Imports of modules go.mod does not require never match a range.
+	Versions string `json:"versions"`
+	// Exempt are slash-separated paths, relative to the scanned directory,
+	// where the package may still be imported. A pattern matches a file as
+	// in path.Match, or a whole directory.
+	Exempt []string `json:"exempt"`
+	// Severity overrides the detector's severity for this package.
+	Severity Severity `json:"severity"`
+
+	// builtin marks the entries shipped with agni, which carry migrations.
+	builtin bool
+	// versions is Versions, parsed.
+	versions versionRange
+}
+
+// deprecatedPackagesOptions are the settings of the deprecated-packages detector.
+type deprecatedPackagesOptions struct {
+	// Packages are checked in addition to the built-in list. An entry with the
+	// same name as a built-in one replaces it.
+	Packages []DeprecatedPackage `json:"packages"`
+	// Symbols enables the check of deprecated standard library functions,
+	// types, constants, variables, methods and fields.
+	Symbols bool `json:"symbols"`
+	// Builtin keeps the built-in list; when false only the configured
+	// packages are checked.
+	Builtin bool `json:"builtin"`
 }
 
 // List of deprecated packages to check
@@ -26,122 +52,226 @@ var deprecatedPackages = []DeprecatedPackage{
 		Description: "Deprecated: use golang.org/x/term instead",
 		Alternative: "golang.org/x/term",
 		Since:       "Go 1.19",
+		builtin:     true,
 	},
 	{
 		Name:        "io/ioutil",
 		Description: "Deprecated: use io and os packages instead",
 		Alternative: "io, os",
 		Since:       "Go 1.16",
+		builtin:     true,
 	},
 	{
 		Name:        "golang.org/x/net/context",
 		Description: "Deprecated: use context package instead",
 		Alternative: "context",
 		Since:       "Go 1.7",
+		builtin:     true,
 	},
 }
 
-// DetectDeprecatedPackages scans for deprecated package imports
-func DetectDeprecatedPackages(path string) {
-	fmt.Println(config.CreateCompactBoxHeader("DEPRECATED PACKAGES", config.BoldRed))
-	fmt.Println()
-	fmt.Println(config.BoldYellow + "🔍 Scanning for deprecated package imports:")
-	fmt.Println()
+func init() {
+	Register(Document(
+		NewDetector(
+			"deprecated-packages",
+			"Reports deprecated packages and uses of deprecated standard library symbols",
+			CategoryDeprecation,
+			SeverityWarning,
+			DetectDeprecatedPackages,
+		),
+		Explanation{
+			Rationale: `Deprecated packages no longer receive improvements, are sometimes removed
+from future releases and often have safer or faster replacements. Imports of
+packages deprecated by Go or by organization standards should be migrated, and
+so should uses of individual standard library symbols whose documentation
+carries a "Deprecated:" notice, such as strings.Title or rand.Seed.`,
+			Bad: `import "io/ioutil"
 
-	if path == "" {
-		fmt.Println("❌ Please enter a valid project folder name.")
-		return
-	}
+data, err := ioutil.ReadFile(path)`,
+			Good: `import "os"
 
-	var foundDeprecated []struct {
-		filePath string
-		line     int
-		pkg      DeprecatedPackage
-	}
+data, err := os.ReadFile(path)`,
+		},
+	))
+}
 
-	err := filepath.Walk(path, func(filePath string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
+// DetectDeprecatedPackages scans for deprecated package imports
+func DetectDeprecatedPackages(pass *Pass) ([]Finding, error) {
+	options := deprecatedPackagesOptions{Symbols: true, Builtin: true}
+	if err := pass.DecodeOptions(&options); err != nil {
+		return nil, err
+	}
+	var builtin []DeprecatedPackage
+	if options.Builtin {
+		builtin = deprecatedPackages
+	}
+	packages := mergeDeprecatedPackages(builtin, options.Packages)
+	for idx := range packages {
+		if err := preparePolicy(&packages[idx]); err != nil {
+			return nil, err
 		}
+	}
+	policy := &packagePolicy{root: pass.Root, packages: packages}
 
-		// Only process Go files
-		if info.IsDir() || !strings.HasSuffix(filePath, ".go") {
-			return nil
+	var files []*File
+	for _, file := range pass.Program.Files {
+		// Skip test files; files with syntax errors still have their imports
+		if !file.IsTest && file.AST != nil {
+			files = append(files, file)
 		}
-
-		// Skip test files if needed
-		if strings.HasSuffix(filePath, "_test.go") {
-			return nil
+	}
+	return pass.ForEachFile(files, func(file *File) ([]Finding, error) {
+		findings, reported := checkFileForDeprecatedPackages(file, pass.Program, policy)
+		if options.Symbols {
+			findings = append(findings, stdlibSymbolFindings(pass.Program, file, reported)...)
 		}
-
-		deprecated := checkFileForDeprecatedPackages(filePath)
-		foundDeprecated = append(foundDeprecated, deprecated...)
-
-		return nil
+		return findings, nil
 	})
+}
 
-	if err != nil {
-		fmt.Printf("Error walking files: %v\n", err)
-		return
+// mergeDeprecatedPackages combines the built-in list with configured entries.
+func mergeDeprecatedPackages(builtin, configured []DeprecatedPackage) []DeprecatedPackage {
+	merged := append([]DeprecatedPackage(nil), builtin...)
+	for _, extra := range configured {
+		replaced := false
+		for idx := range merged {
+			if merged[idx].Name == extra.Name {
+				merged[idx] = extra
+				replaced = true
+			}
+		}
+		if !replaced {
+			merged = append(merged, extra)
+		}
 	}
+	return merged
+}
 
-	// Report results
-	if len(foundDeprecated) == 0 {
-		fmt.Println(config.BoldGreen + "✅ No deprecated packages found!")
-		return
+// preparePolicy validates the version range and exemptions of an entry.
+func preparePolicy(deprecated *DeprecatedPackage) error {
+	if deprecated.Name == "" {
+		return fmt.Errorf("invalid options: deprecated package without a name")
+	}
+	if deprecated.Versions != "" {
+		versions, err := parseVersionRange(deprecated.Versions)
+		if err != nil {
+			return fmt.Errorf("invalid options: %s: %v", deprecated.Name, err)
+		}
+		deprecated.versions = versions
+	}
+	for _, pattern := range deprecated.Exempt {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid options: %s: exempt pattern %q: %v", deprecated.Name, pattern, err)
+		}
 	}
+	return nil
+}
 
-	fmt.Println(config.BoldYellow + "⚠️  Found deprecated packages:")
-	fmt.Println()
+// packagePolicy matches the imports of a file against the deprecated packages.
+type packagePolicy struct {
+	root     string
+	packages []DeprecatedPackage
+	goMods   goModCache
+}
 
-	for _, item := range foundDeprecated {
-		fmt.Printf(config.Red+"📁 %s:%d"+config.Reset+" - "+config.BoldYellow+"%s"+config.Reset+"\n",
-			item.filePath, item.line, item.pkg.Name)
-		fmt.Printf("   "+config.Cyan+"Description: %s"+config.Reset+"\n", item.pkg.Description)
-		fmt.Printf("   "+config.Green+"Alternative: %s"+config.Reset+"\n", item.pkg.Alternative)
-		fmt.Printf("   "+config.Purple+"Since: %s"+config.Reset+"\n", item.pkg.Since)
-		fmt.Println()
+// matches reports whether the entry applies to importPath in file, and
+// describes the required module version when a range matched.
+func (policy *packagePolicy) matches(deprecated DeprecatedPackage, file *File, importPath string) (bool, string) {
+	if prefix, ok := strings.CutSuffix(deprecated.Name, "/..."); ok {
+		if importPath != prefix && !strings.HasPrefix(importPath, prefix+"/") {
+			return false, ""
+		}
+	} else if importPath != deprecated.Name {
+		return false, ""
+	}
+	if isExempt(policy.root, file.Path, deprecated.Exempt) {
+		return false, ""
+	}
+	if deprecated.versions == nil {
+		return true, ""
+	}
+	goMod := policy.goMods.lookup(filepath.Dir(file.Path))
+	if goMod == nil {
+		return false, ""
+	}
+	module, version, ok := goMod.Version(importPath)
+	if !ok {
+		return false, ""
+	}
+	parsed, err := parseSemver(version)
+	if err != nil || !deprecated.versions.Contains(parsed) {
+		return false, ""
 	}
+	return true, module + "@" + version
 }
 
-// checkFileForDeprecatedPackages checks a single file for deprecated imports
-func checkFileForDeprecatedPackages(filePath string) []struct {
-	filePath string
-	line     int
-	pkg      DeprecatedPackage
-} {
-	var found []struct {
-		filePath string
-		line     int
-		pkg      DeprecatedPackage
+// isExempt reports whether file lies under one of the exempt patterns,
+// which are relative to root.
+func isExempt(root, file string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return false
 	}
-
-	fset := token.NewFileSet()
-	node, err := parser.ParseFile(fset, filePath, nil, parser.ImportsOnly)
+	rel, err := filepath.Rel(root, file)
 	if err != nil {
-		return found
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(path.Clean(pattern), "/")
+		if matched, _ := path.Match(pattern, rel); matched {
+			return true
+		}
+		// A pattern naming a directory covers everything below it
+		for dir := path.Dir(rel); dir != "."; dir = path.Dir(dir) {
+			if matched, _ := path.Match(pattern, dir); matched {
+				return true
+			}
+		}
 	}
+	return false
+}
+
+// checkFileForDeprecatedPackages checks a single file for deprecated imports,
+// and returns the import paths it reported. Imports of built-in deprecated
+// packages carry the edits migrating the file.
+func checkFileForDeprecatedPackages(file *File, program *Program, policy *packagePolicy) ([]Finding, map[string]bool) {
+	var found []Finding
+	reported := make(map[string]bool)
+	node := file.AST
+	fset := program.Fset
 
 	// Check each import
 	for _, importSpec := range node.Imports {
 		importPath := strings.Trim(importSpec.Path.Value, `"`)
 
 		// Check if this import is deprecated
-		for _, deprecated := range deprecatedPackages {
-			if importPath == deprecated.Name {
-				position := fset.Position(importSpec.Pos())
-				found = append(found, struct {
-					filePath string
-					line     int
-					pkg      DeprecatedPackage
-				}{
-					filePath: filePath,
-					line:     position.Line,
-					pkg:      deprecated,
-				})
+		for _, deprecated := range policy.packages {
+			matched, requirement := policy.matches(deprecated, file, importPath)
+			if !matched {
+				continue
+			}
+			message := fmt.Sprintf("Package '%s' is deprecated", importPath)
+			if deprecated.Since != "" {
+				message += " since " + deprecated.Since
+			}
+			if requirement != "" {
+				message += " (go.mod requires " + requirement + ")"
+			}
+			if deprecated.Description != "" {
+				message += ". " + deprecated.Description
+			}
+			finding := NewFinding(fset.Position(importSpec.Path.Pos()), fset.Position(importSpec.Path.End()), message)
+			if deprecated.Alternative != "" {
+				finding.SuggestedFix = "Use " + deprecated.Alternative
+			}
+			finding.Severity = deprecated.Severity
+			if deprecated.builtin {
+				finding.Edits = migrationEdits(program, file, importSpec)
 			}
+			found = append(found, finding)
+			reported[importPath] = true
 		}
 	}
 
-	return found
+	return found, reported
 }
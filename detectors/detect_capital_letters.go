@@ -3,77 +3,126 @@ package detectors
 import (
 	"fmt"
 	"go/ast"
-	"go/parser"
 	"go/token"
-	"log"
-	"os"
-	"path/filepath"
+	"go/types"
 	"strings"
 	"unicode"
-
-	"github.com/Aadi-IRON/agni/config"
 )
 
-func DetectCapitalVars(path string) {
-	fmt.Println(config.CreateCompactBoxHeader("CAPITAL LETTERS", config.BoldBlue))
-	if path == "" {
-		fmt.Println("Please pass a valid directory name.", path)
-		return
+func init() {
+	Register(Document(
+		NewDetector(
+			"capital-vars",
+			"Reports local variables, parameters and named results that start with a capital letter",
+			CategoryStyle,
+			SeverityWarning,
+			DetectCapitalVars,
+		),
+		Explanation{
+			Rationale: `In Go, capitalization controls visibility. Local variables, parameters and
+named results are never exported, so starting them with a capital letter
+misleads readers into thinking they are package-level identifiers.`,
+			Bad: `func Total(Items []Item) (Sum int) {
+	Count := len(Items)
+	...
+}`,
+			Good: `func Total(items []Item) (sum int) {
+	count := len(items)
+	...
+}`,
+		},
+	))
+}
+
+// capitalVarsOptions are the settings of the capital-vars detector.
+type capitalVarsOptions struct {
+	// SkipFiles are file name suffixes that are not checked.
+	SkipFiles []string `json:"skip-files"`
+}
+
+func DetectCapitalVars(pass *Pass) ([]Finding, error) {
+	options := capitalVarsOptions{
+		SkipFiles: []string{"_test.go", "const.go", "Const.go", "message.go", "Message.go", "Messages.go", "messages.go"},
 	}
-	fmt.Println()
-	fmt.Printf(config.BoldBlue + "🔍 Detecting variables and function parameters with capital letters :---")
-	fmt.Println()
-	functionSet := token.NewFileSet()
-	err := filepath.Walk(path, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if info.IsDir() ||
-			strings.HasSuffix(path, "_test.go") ||
-			!strings.HasSuffix(path, ".go") ||
-			strings.HasSuffix(path, "const.go") ||
-			strings.HasSuffix(path, "Const.go") ||
-			strings.HasSuffix(path, "message.go") ||
-			strings.HasSuffix(path, "Message.go") ||
-			strings.HasSuffix(path, "Messages.go") ||
-			strings.HasSuffix(path, "messages.go") {
-			return nil
+	if err := pass.DecodeOptions(&options); err != nil {
+		return nil, err
+	}
+	var files []*File
+	for _, file := range pass.Program.Files {
+		if !skipFile(file.Path, options.SkipFiles) {
+			files = append(files, file)
 		}
-		CheckFile(path, functionSet)
-		return nil
+	}
+	return pass.ForEachFile(files, func(file *File) ([]Finding, error) {
+		return CheckFile(file, pass.Program)
 	})
-	fmt.Println()
-	fmt.Println(config.Green + "FINISHED")
-	if err != nil {
-		log.Fatal(err)
+}
+
+// skipFile reports whether a path ends with one of the given suffixes.
+func skipFile(path string, suffixes []string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(path, suffix) {
+			return true
+		}
 	}
+	return false
 }
 
-// Checks a single Go file for capitalized variable/parameter names
-func CheckFile(path string, fset *token.FileSet) {
-	node, err := parser.ParseFile(fset, path, nil, parser.AllErrors)
-	if err != nil {
-		log.Println("Error parsing:", path, err)
-		return
+// Checks a single Go file for capitalized variable/parameter names. In
+// type-checked files each finding carries the edits renaming the variable at
+// all of its references, unless the lower-case name would clash.
+func CheckFile(file *File, program *Program) ([]Finding, error) {
+	if file.ParseErr != nil {
+		return nil, fmt.Errorf("error parsing %s: %v", file.Path, file.ParseErr)
 	}
+	node := file.AST
+	fset := program.Fset
+	info := program.TypesInfo()
 
-	// Track if we're inside a function
-	var insideFunction bool
+	var findings []Finding
+	report := func(kind string, name *ast.Ident) {
+		finding := NewFinding(fset.Position(name.Pos()), fset.Position(name.End()),
+			fmt.Sprintf("Capitalized %s '%s'", kind, name.Name))
+		finding.SuggestedFix = fmt.Sprintf("Rename '%s' to start with a lower-case letter", name.Name)
+		if file.Checked {
+			// Package-level variables are never renamed: lower-casing one
+			// would unexport it
+			if variable, ok := info.ObjectOf(name).(*types.Var); ok && variable.Pkg() != nil && variable.Parent() != variable.Pkg().Scope() {
+				finding.Edits, _ = renameEdits(program, variable, lowerName(name.Name))
+			}
+		}
+		findings = append(findings, finding)
+	}
+
+	// Track how many functions enclose the current node: Inspect calls back
+	// with nil when it leaves a node, so the visited nodes are kept on a stack
+	var stack []ast.Node
+	functionDepth := 0
 
 	ast.Inspect(node, func(astNode ast.Node) bool {
+		if astNode == nil {
+			switch stack[len(stack)-1].(type) {
+			case *ast.FuncDecl, *ast.FuncLit:
+				functionDepth--
+			}
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		stack = append(stack, astNode)
+		insideFunction := functionDepth > 0
+
 		switch stmt := astNode.(type) {
 
 		case *ast.FuncDecl:
 			// We're entering a function
-			insideFunction = true
+			functionDepth++
 
 			// Function parameters
 			if stmt.Type.Params != nil {
 				for _, param := range stmt.Type.Params.List {
 					for _, name := range param.Names {
 						if IsCapitalized(name.Name) {
-							position := fset.Position(name.Pos())
-							log.Printf(config.Yellow+"Capitalized function parameter"+config.BoldRed+" '%s'"+config.Yellow+" at %s\n", name.Name, position)
+							report("function parameter", name)
 						}
 					}
 				}
@@ -83,8 +132,7 @@ func CheckFile(path string, fset *token.FileSet) {
 				for _, result := range stmt.Type.Results.List {
 					for _, name := range result.Names {
 						if IsCapitalized(name.Name) {
-							position := fset.Position(name.Pos())
-							log.Printf(config.Yellow+"Capitalized named return variable"+config.BoldRed+" '%s'"+config.Yellow+" at %s\n", name.Name, position)
+							report("named return variable", name)
 						}
 					}
 				}
@@ -92,7 +140,7 @@ func CheckFile(path string, fset *token.FileSet) {
 
 		case *ast.FuncLit:
 			// We're entering an anonymous function
-			insideFunction = true
+			functionDepth++
 
 		case *ast.GenDecl:
 			if stmt.Tok == token.CONST {
@@ -107,8 +155,7 @@ func CheckFile(path string, fset *token.FileSet) {
 						if valSpec, ok := spec.(*ast.ValueSpec); ok {
 							for _, name := range valSpec.Names {
 								if IsCapitalized(name.Name) {
-									position := fset.Position(name.Pos())
-									log.Printf(config.Yellow+"Capitalized local variable"+config.BoldRed+" '%s'"+config.Yellow+" at %s\n", name.Name, position)
+									report("local variable", name)
 								}
 							}
 						}
@@ -131,14 +178,14 @@ func CheckFile(path string, fset *token.FileSet) {
 								continue
 							}
 						}
-						position := fset.Position(ident.Pos())
-						log.Printf(config.Yellow+"Capitalized short variable"+config.BoldRed+" '%s'"+config.Yellow+" at %s\n", ident.Name, position)
+						report("short variable", ident)
 					}
 				}
 			}
 		}
 		return true
 	})
+	return findings, nil
 }
 
 // Checks if a name starts with a capital letter
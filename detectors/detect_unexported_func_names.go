@@ -1,17 +1,36 @@
 package detectors
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"go/ast"
-	"go/parser"
 	"go/token"
-	"os"
-	"path/filepath"
+	"go/types"
 	"strings"
-
-	"github.com/Aadi-IRON/agni/config"
+	"unicode/utf8"
 )
 
+func init() {
+	Register(Document(
+		NewDetector(
+			"exported-but-internal",
+			"Reports exported functions that are only used inside their own package",
+			CategoryStyle,
+			SeverityInfo,
+			DetectExportedButInternalFuncs,
+		),
+		Explanation{
+			Rationale: `An exported function is part of the package's API. When nothing outside the
+package calls it, exporting it only widens the surface other packages may
+start depending on. Unexport functions that are used only internally.`,
+			Bad: `// Only called from within package billing.
+func ComputeTax(amount int) int { ... }`,
+			Good: `func computeTax(amount int) int { ... }`,
+		},
+	))
+}
+
 type FuncInfo struct {
 	Name          string
 	FilePath      string
@@ -19,104 +38,193 @@ type FuncInfo struct {
 	Column        int
 	Package       string
 	UsedElsewhere bool
+	// object is the declared function; nil when its file was not type-checked.
+	object types.Object
+	pkg    *Package
+	decl   *ast.FuncDecl
 }
 
-func DetectExportedButInternalFuncs(filePath string) {
-	fmt.Println(config.CreateCompactBoxHeader("EXPORTED FUNCTIONS THAT SHOULD BE UNEXPORTED", config.BoldPurple))
-	fmt.Println("")
-	if filePath == "" {
-		fmt.Println("❌ Please enter a valid project folder name.")
-		return
-	}
-
+func DetectExportedButInternalFuncs(pass *Pass) ([]Finding, error) {
 	var exportedFuncs []FuncInfo
-	fset := token.NewFileSet()
+	var fileErrs []error
+	fset := pass.Program.Fset
+	info := pass.Program.TypesInfo()
 
-	// Pass 1: Collect all exported functions
-	err := filepath.Walk(filePath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			fmt.Printf("Error reading file %s: %v\n", path, err)
-			return nil
+	// Only non-test files that parsed take part in both passes
+	var files []*File
+	for _, file := range pass.Program.Files {
+		if file.IsTest {
+			continue
 		}
-		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
-			return nil
-		}
-
-		node, err := parser.ParseFile(fset, path, nil, parser.AllErrors)
-		if err != nil {
-			fmt.Printf("Failed to parse %s: %v\n", path, err)
-			return nil
+		if file.ParseErr != nil {
+			fileErrs = append(fileErrs, fmt.Errorf("failed to parse %s: %v", file.Path, file.ParseErr))
+			continue
 		}
+		files = append(files, file)
+	}
 
-		for _, decl := range node.Decls {
-			if function, ok := decl.(*ast.FuncDecl); ok && function.Name.IsExported() {
+	// Pass 1: Collect all exported functions. Methods are left out: they are
+	// often called only through interfaces, which no use site reveals.
+	for _, file := range files {
+		for _, decl := range file.AST.Decls {
+			if function, ok := decl.(*ast.FuncDecl); ok && function.Recv == nil && function.Name.IsExported() {
 				position := fset.Position(function.Pos())
 				exportedFuncs = append(exportedFuncs, FuncInfo{
 					Name:     function.Name.Name,
-					FilePath: path,
+					FilePath: file.Path,
 					Line:     position.Line,
 					Column:   position.Column,
-					Package:  node.Name.Name,
+					Package:  file.AST.Name.Name,
+					object:   info.Defs[function.Name],
+					pkg:      file.Package,
+					decl:     function,
 				})
 			}
 		}
-		return nil
-	})
-
-	if err != nil {
-		fmt.Printf("Error walking files: %v\n", err)
-		return
 	}
 
-	// Pass 2: Check if exported functions are used in other packages
-	err = filepath.Walk(filePath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			fmt.Printf("Error reading file %s: %v\n", path, err)
-			return nil
-		}
-		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
-			return nil
-		}
-
-		node, err := parser.ParseFile(fset, path, nil, parser.AllErrors)
-		if err != nil {
-			fmt.Printf("Failed to parse %s: %v\n", path, err)
-			return nil
+	// Index the functions by object, and by name for the syntactic fallback
+	byObject := make(map[types.Object]int)
+	byName := make(map[string][]int)
+	for idx, function := range exportedFuncs {
+		if function.object != nil {
+			byObject[function.object] = idx
 		}
+		byName[function.Name] = append(byName[function.Name], idx)
+	}
 
-		ast.Inspect(node, func(astNode ast.Node) bool {
-			if ident, ok := astNode.(*ast.Ident); ok {
-				for idx := range exportedFuncs {
-					if ident.Name == exportedFuncs[idx].Name &&
-						node.Name.Name != exportedFuncs[idx].Package {
-						exportedFuncs[idx].UsedElsewhere = true
-					}
+	// Pass 2: Check if exported functions are used in other packages. Uses are
+	// resolved to the object they denote, so an unrelated function of the same
+	// name elsewhere does not count. Identifiers of files that could not be
+	// type-checked, and functions declared in such files, are matched by name.
+	for _, file := range files {
+		ast.Inspect(file.AST, func(astNode ast.Node) bool {
+			ident, ok := astNode.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			if file.Checked {
+				if idx, found := byObject[info.Uses[ident]]; found && exportedFuncs[idx].pkg != file.Package {
+					exportedFuncs[idx].UsedElsewhere = true
+				}
+			}
+			for _, idx := range byName[ident.Name] {
+				if exportedFuncs[idx].pkg == file.Package {
+					continue
+				}
+				if !file.Checked || exportedFuncs[idx].object == nil {
+					exportedFuncs[idx].UsedElsewhere = true
 				}
 			}
 			return true
 		})
-		return nil
-	})
-
-	if err != nil {
-		fmt.Printf("Error walking files: %v\n", err)
-		return
 	}
 
-	// Output results
-	missing := 0
+	var findings []Finding
 	for _, function := range exportedFuncs {
 		if !function.UsedElsewhere {
-			fmt.Printf("%s:%d:%d - %s%s%s should be unexported (used only inside package '%s')\n",
-				function.FilePath, function.Line, function.Column,
-				config.BoldYellow, function.Name, config.Reset, function.Package)
-			missing++
+			position := token.Position{Filename: function.FilePath, Line: function.Line, Column: function.Column}
+			finding := NewFinding(position, token.Position{},
+				fmt.Sprintf("%s should be unexported (used only inside package '%s')", function.Name, function.Package))
+			finding.SuggestedFix = "Rename the function to start with a lower-case letter"
+			finding.Edits = unexportEdits(pass.Program, function)
+			findings = append(findings, finding)
 		}
 	}
+	return findings, errors.Join(fileErrs...)
+}
+
+// unexportEdits renames the function and every reference to it, including
+// the leading word of its doc comment and [Name] doc links in its package.
+// It returns nil when a reference lies outside the package (an external
+// test, say), may hide in a file that was not type-checked, or is a comment
+// that mentions the function by its bare name, which cannot be told apart
+// from prose.
+func unexportEdits(program *Program, function FuncInfo) []TextEdit {
+	if function.object == nil {
+		return nil
+	}
+	for _, ident := range program.References(function.object) {
+		file := program.File(program.Position(ident.Pos()).Filename)
+		if file == nil || file.Package != function.pkg {
+			return nil
+		}
+	}
+	for _, file := range function.pkg.Files {
+		if !file.Checked && bytes.Contains(file.Src, []byte(function.Name)) {
+			return nil
+		}
+	}
+	newName := lowerName(function.Name)
+	edits, ok := renameEdits(program, function.object, newName)
+	if !ok {
+		return nil
+	}
+	comments, ok := commentEdits(program, function, newName)
+	if !ok {
+		return nil
+	}
+	return append(edits, comments...)
+}
 
-	if missing == 0 {
-		fmt.Println(config.Cyan + "🎉 No incorrectly exported functions found.")
+// commentEdits renames the mentions of the function in comments. A mention
+// is rewritten when it is the leading word of the function's doc comment or
+// a [Name] doc link in the function's package. It returns false for any
+// other mention in the package, and for a [pkg.Name] link from another
+// package, which the rename would break.
+func commentEdits(program *Program, function FuncInfo, newName string) ([]TextEdit, bool) {
+	var leading *ast.Comment
+	if function.decl.Doc != nil {
+		leading = function.decl.Doc.List[0]
+	}
+	var edits []TextEdit
+	for _, file := range program.Files {
+		if file.AST == nil {
+			continue
+		}
+		ownPackage := file.Package == function.pkg
+		for _, group := range file.AST.Comments {
+			for _, comment := range group.List {
+				text := comment.Text
+				// Skip the comment marker so that "//Name" counts as leading
+				prefix := len(text) - len(strings.TrimLeft(strings.TrimPrefix(text, "//"), " \t"))
+				for offset := 0; ; {
+					idx := strings.Index(text[offset:], function.Name)
+					if idx < 0 {
+						break
+					}
+					start := offset + idx
+					offset = start + len(function.Name)
+					before, _ := utf8.DecodeLastRuneInString(text[:start])
+					if isIdentRune(before) || !startsWithWord(text[start:], function.Name) {
+						continue
+					}
+					if before == '.' {
+						// Client.Name names something else; pkg.Name names the
+						// function from outside its package
+						qualifier := trailingIdent(text[:start-1])
+						if !ownPackage && qualifier == function.Package {
+							return nil, false
+						}
+						continue
+					}
+					if !ownPackage {
+						continue
+					}
+					link := before == '[' && strings.HasPrefix(text[offset:], "]")
+					if !link && !(comment == leading && start == prefix && strings.HasPrefix(text, "//")) {
+						return nil, false
+					}
+					position := program.Position(comment.Pos())
+					edits = append(edits, TextEdit{
+						File:    position.Filename,
+						Start:   position.Offset + start,
+						End:     position.Offset + offset,
+						NewText: newName,
+					})
+				}
+			}
+		}
 	}
-	fmt.Println("")
-	fmt.Println("")
+	return edits, true
 }
@@ -0,0 +1,74 @@
+package detectors
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnexportEdits(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     map[string]string
+		wantEdits []string
+	}{
+		{
+			name: "doc comment and doc links",
+			files: map[string]string{"svc/svc.go": `package svc
+
+// Load returns two. See [Load].
+func Load() int { return 2 }
+`},
+			wantEdits: []string{
+				`svc/svc.go:4: "Load" -> "load"`,
+				`svc/svc.go:3: "Load" -> "load"`,
+				`svc/svc.go:3: "Load" -> "load"`,
+			},
+		},
+		{
+			name: "bare mention in another doc comment",
+			files: map[string]string{"svc/svc.go": `package svc
+
+func Fetch() int { return 1 }
+
+// Deprecated: use Fetch.
+func get() int { return Fetch() }
+`},
+			wantEdits: []string{},
+		},
+		{
+			name: "qualified mention of something else",
+			files: map[string]string{"svc/svc.go": `package svc
+
+// Load calls Client.Load.
+func Load() int { return 2 }
+`},
+			wantEdits: []string{
+				`svc/svc.go:4: "Load" -> "load"`,
+				`svc/svc.go:3: "Load" -> "load"`,
+			},
+		},
+		{
+			name: "doc link from another package",
+			files: map[string]string{
+				"svc/svc.go": `package svc
+
+func Load() int { return 2 }
+`,
+				"other/other.go": `package other
+
+// See [svc.Load].
+func use() {}
+`,
+			},
+			wantEdits: []string{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root, results := runDetectors(t, test.files, nil, "exported-but-internal")
+			if got := describeEdits(t, root, results); !reflect.DeepEqual(got, test.wantEdits) {
+				t.Errorf("edits = %q, want %q", got, test.wantEdits)
+			}
+		})
+	}
+}
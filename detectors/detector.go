@@ -0,0 +1,140 @@
+package detectors
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity describes how serious the findings of a detector are.
+type Severity int
+
+// The zero Severity means "use the detector's default".
+const (
+	SeverityInfo Severity = iota + 1
+	SeverityWarning
+	SeverityError
+)
+
+// String returns the lower-case name of the severity.
+func (severity Severity) String() string {
+	switch severity {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	}
+	return fmt.Sprintf("severity(%d)", int(severity))
+}
+
+// ParseSeverity converts a name such as "warning" into a Severity.
+func ParseSeverity(name string) (Severity, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "info":
+		return SeverityInfo, nil
+	case "warning", "warn":
+		return SeverityWarning, nil
+	case "error":
+		return SeverityError, nil
+	}
+	return SeverityInfo, fmt.Errorf("unknown severity %q (expected info, warning or error)", name)
+}
+
+// MarshalText encodes the severity as its name.
+func (severity Severity) MarshalText() ([]byte, error) {
+	return []byte(severity.String()), nil
+}
+
+// UnmarshalText decodes a severity name.
+func (severity *Severity) UnmarshalText(text []byte) error {
+	parsed, err := ParseSeverity(string(text))
+	if err != nil {
+		return err
+	}
+	*severity = parsed
+	return nil
+}
+
+// Category groups detectors that look for the same kind of problem.
+type Category string
+
+const (
+	CategoryUnused      Category = "unused"
+	CategoryStyle       Category = "style"
+	CategoryDeprecation Category = "deprecation"
+	CategoryMessages    Category = "messages"
+	CategoryCorrectness Category = "correctness"
+)
+
+// Detector is a single check that Agni can run against a project.
+type Detector interface {
+	// Name is the unique, kebab-case identifier used to select the detector.
+	Name() string
+	// Description is a one-line summary shown by the CLI.
+	Description() string
+	Category() Category
+	// Severity is the default severity of the detector's findings.
+	Severity() Severity
+	// Run executes the detector and returns its findings. A non-nil error
+	// may be returned alongside findings when only part of the run failed.
+	Run(pass *Pass) ([]Finding, error)
+}
+
+// funcDetector adapts a plain function to the Detector interface.
+type funcDetector struct {
+	name        string
+	description string
+	category    Category
+	severity    Severity
+	run         func(pass *Pass) ([]Finding, error)
+}
+
+// NewDetector creates a Detector backed by the given run function.
+func NewDetector(name, description string, category Category, severity Severity, run func(pass *Pass) ([]Finding, error)) Detector {
+	return &funcDetector{
+		name:        name,
+		description: description,
+		category:    category,
+		severity:    severity,
+		run:         run,
+	}
+}
+
+func (detector *funcDetector) Name() string        { return detector.name }
+func (detector *funcDetector) Description() string { return detector.description }
+func (detector *funcDetector) Category() Category  { return detector.category }
+func (detector *funcDetector) Severity() Severity  { return detector.severity }
+
+func (detector *funcDetector) Run(pass *Pass) ([]Finding, error) {
+	return detector.run(pass)
+}
+
+// Explanation is the long-form documentation of a detector.
+type Explanation struct {
+	// Rationale explains why the detector exists and what it looks for.
+	Rationale string
+	// Bad is an example of code the detector reports.
+	Bad string
+	// Good is the corrected version of Bad.
+	Good string
+}
+
+// Explainer is implemented by detectors that document themselves.
+type Explainer interface {
+	Explain() Explanation
+}
+
+// documentedDetector attaches an Explanation to another detector.
+type documentedDetector struct {
+	Detector
+	explanation Explanation
+}
+
+// Document returns a detector that behaves like detector and also implements
+// Explainer with the given explanation.
+func Document(detector Detector, explanation Explanation) Detector {
+	return &documentedDetector{Detector: detector, explanation: explanation}
+}
+
+func (detector *documentedDetector) Explain() Explanation { return detector.explanation }
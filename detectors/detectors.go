@@ -1,13 +1,197 @@
 package detectors
 
-func RunAll(path string) {
-	DetectUnusedParams(path)
-	DetectUnusedConstants(path)
-	DetectUnusedMessages(path)
-	DetectUnDefinedMessageKeys(path)
-	DetectCapitalVars(path)
-	DetectDeprecatedPackages(path)
-	DetectExportedButInternalFuncs(path)
-	RunDeadCode(path)
-	// DetectFuncWithManyParams(path)
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Aadi-IRON/agni/config"
+)
+
+// defaultOrder is the order in which the built-in detectors run.
+var defaultOrder = []string{
+	"unused-params",
+	"unused-constants",
+	"enum-switch",
+	"duplicate-constants",
+	"unused-messages",
+	"undefined-message-keys",
+	"message-locales",
+	"capital-vars",
+	"deprecated-packages",
+	"deprecated-internal",
+	"exported-but-internal",
+	"dead-code",
+	SuppressionsRule,
+}
+
+// Result holds the outcome of running a single detector.
+type Result struct {
+	Detector Detector
+	Findings []Finding
+	// Err is set when the detector failed, fully or partially.
+	Err      error
+	Duration time.Duration
+}
+
+// RunAll runs every detector enabled by the project configuration of the
+// given path.
+func RunAll(path string) []Result {
+	project, err := config.LoadProject(path)
+	if err == nil {
+		err = CheckProject(project)
+	}
+	if err != nil {
+		return failAll(All(), err)
+	}
+	return Run(path, Enabled(All(), project), Options{Project: project})
+}
+
+// Enabled filters out the detectors disabled in the project configuration.
+func Enabled(detectors []Detector, project *config.Project) []Detector {
+	var enabled []Detector
+	for _, detector := range detectors {
+		if project.IsEnabled(detector.Name()) {
+			enabled = append(enabled, detector)
+		}
+	}
+	return enabled
+}
+
+// Options control how Run executes detectors.
+type Options struct {
+	// Project holds the detector settings; it may be nil.
+	Project *config.Project
+	// Jobs bounds how many detectors, and how many file-level tasks, run at
+	// once. Values below one use GOMAXPROCS.
+	Jobs int
+	// Loading, when set, spins while the project is parsed.
+	Loading *config.Spinner
+	// Progress, when set, advances as each detector finishes. Detectors
+	// finish concurrently, so it must be safe for concurrent use.
+	Progress *config.ProgressBar
+}
+
+// failAll returns a result carrying err for every detector.
+func failAll(detectors []Detector, err error) []Result {
+	results := make([]Result, 0, len(detectors))
+	for _, detector := range detectors {
+		results = append(results, Result{Detector: detector, Err: err})
+	}
+	return results
+}
+
+// Run runs the given detectors concurrently against the given path. Results
+// are returned in the order of detectors and their findings are sorted, so
+// the outcome does not depend on scheduling.
+func Run(path string, detectors []Detector, options Options) []Result {
+	if path == "" {
+		return failAll(detectors, errors.New("please pass a valid directory path"))
+	}
+
+	pool := newWorkerPool(options.Jobs)
+	// Parse the project once and share it with every detector
+	if options.Loading != nil {
+		options.Loading.Start()
+	}
+	program, err := LoadProgram(path, pool.size())
+	if options.Loading != nil {
+		options.Loading.Stop()
+	}
+	if err != nil {
+		return failAll(detectors, fmt.Errorf("error loading %s: %v", path, err))
+	}
+
+	// Directives are collected up front for detectors that consult them
+	suppressionDirectives := newSuppressionIndex(program)
+	// advance moves the progress on by one detector
+	advance := func() {
+		if options.Progress != nil {
+			options.Progress.Increment()
+		}
+	}
+	results := make([]Result, len(detectors))
+	suppressions := -1
+	var suppressionsSeverity Severity
+	var wait sync.WaitGroup
+	// Detectors get their own limit: they mostly wait on file-level tasks,
+	// which are bounded by the shared pool
+	running := make(chan struct{}, pool.size())
+	for idx, detector := range detectors {
+		results[idx].Detector = detector
+		settings := options.Project.Detector(detector.Name())
+		severity, err := configuredSeverity(detector, settings)
+		if err != nil {
+			results[idx].Err = err
+			advance()
+			continue
+		}
+		if detector.Name() == SuppressionsRule {
+			// Computed below, once every other detector has reported
+			suppressions, suppressionsSeverity = idx, severity
+			advance()
+			continue
+		}
+
+		running <- struct{}{}
+		wait.Add(1)
+		go func(result *Result) {
+			defer func() {
+				<-running
+				wait.Done()
+			}()
+			pass := &Pass{Root: path, Program: program, Options: settings.Options, pool: pool,
+				rule: detector.Name(), suppressions: suppressionDirectives}
+			start := time.Now()
+			findings, err := detector.Run(pass)
+			setDefaults(findings, detector.Name(), severity)
+			result.Findings = findings
+			result.Err = err
+			result.Duration = time.Since(start)
+			advance()
+		}(&results[idx])
+	}
+	wait.Wait()
+	if options.Progress != nil {
+		options.Progress.Finish()
+	}
+
+	// Suppression directives apply to every detector's findings, whether or
+	// not the suppressions detector itself was selected
+	start := time.Now()
+	problems := applySuppressions(suppressionDirectives, results)
+	if suppressions >= 0 {
+		setDefaults(problems, SuppressionsRule, suppressionsSeverity)
+		results[suppressions].Findings = problems
+		results[suppressions].Duration = time.Since(start)
+	}
+	return results
+}
+
+// configuredSeverity returns the severity of a detector's findings, taking the
+// project configuration into account.
+func configuredSeverity(detector Detector, settings config.DetectorConfig) (Severity, error) {
+	if settings.Severity == "" {
+		return detector.Severity(), nil
+	}
+	severity, err := ParseSeverity(settings.Severity)
+	if err != nil {
+		return 0, fmt.Errorf("invalid configuration: %v", err)
+	}
+	return severity, nil
+}
+
+// setDefaults fills in the rule and severity of findings that left them unset
+// and sorts the findings.
+func setDefaults(findings []Finding, rule string, severity Severity) {
+	for idx := range findings {
+		if findings[idx].RuleID == "" {
+			findings[idx].RuleID = rule
+		}
+		if findings[idx].Severity == 0 {
+			findings[idx].Severity = severity
+		}
+	}
+	SortFindings(findings)
 }
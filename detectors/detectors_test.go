@@ -0,0 +1,86 @@
+package detectors
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Aadi-IRON/agni/config"
+)
+
+// runDetectors writes files, keyed by slash-separated path, into a new module
+// and runs the named detectors over it. A go.mod is added unless files has one.
+func runDetectors(t *testing.T, files map[string]string, project *config.Project, rules ...string) (string, []Result) {
+	t.Helper()
+	root := t.TempDir()
+	if _, ok := files["go.mod"]; !ok {
+		files["go.mod"] = "module example.com/app\n\ngo 1.22\n"
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var selected []Detector
+	for _, rule := range rules {
+		detector, ok := Lookup(rule)
+		if !ok {
+			t.Fatalf("unknown detector %q", rule)
+		}
+		selected = append(selected, detector)
+	}
+	results := Run(root, selected, Options{Project: project, Jobs: 1})
+	for _, result := range results {
+		if result.Err != nil {
+			t.Fatalf("%s: %v", result.Detector.Name(), result.Err)
+		}
+	}
+	return root, results
+}
+
+// describeFindings returns each finding as "path:line severity: message".
+func describeFindings(root string, results []Result) []string {
+	descriptions := []string{}
+	for _, result := range results {
+		for _, finding := range result.Findings {
+			descriptions = append(descriptions, fmt.Sprintf("%s:%d %s: %s",
+				relativePath(root, finding.File), finding.Line, finding.Severity, finding.Message))
+		}
+	}
+	return descriptions
+}
+
+// describeEdits returns each edit as "path:line: old -> new", with the
+// replaced text read from the file.
+func describeEdits(t *testing.T, root string, results []Result) []string {
+	t.Helper()
+	descriptions := []string{}
+	for _, result := range results {
+		for _, finding := range result.Findings {
+			for _, edit := range finding.Edits {
+				src, err := os.ReadFile(edit.File)
+				if err != nil {
+					t.Fatal(err)
+				}
+				line := 1 + bytes.Count(src[:edit.Start], []byte("\n"))
+				descriptions = append(descriptions, fmt.Sprintf("%s:%d: %q -> %q",
+					relativePath(root, edit.File), line, src[edit.Start:edit.End], edit.NewText))
+			}
+		}
+	}
+	return descriptions
+}
+
+func relativePath(root, path string) string {
+	relative, err := filepath.Rel(root, path)
+	if err != nil {
+		return path
+	}
+	return filepath.ToSlash(relative)
+}
@@ -0,0 +1,225 @@
+package detectors
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+func init() {
+	Register(Document(
+		NewDetector(
+			"duplicate-constants",
+			"Reports constants with the same value and literals that repeat an exported constant",
+			CategoryStyle,
+			SeverityInfo,
+			DetectDuplicateConstants,
+		),
+		Explanation{
+			Rationale: `Shared literals belong in one constant. Two constants of the const files
+spelling out the same value drift apart as soon as one of them changes, and
+so does a literal typed again where an exported constant already holds it.
+Short values such as "", 0 or 10 coincide by accident and are ignored.`,
+			Bad: `// config/const.go
+const (
+	StatusActive = "active"
+	StateActive  = "active"
+)
+
+// orders/orders.go
+if order.Status == "active" { ... }`,
+			Good: `if order.Status == config.StatusActive { ... }`,
+		},
+	))
+}
+
+// duplicateConstantsOptions are the settings of the duplicate-constants detector.
+type duplicateConstantsOptions struct {
+	// Files are the const files, relative to the root, whose constants must
+	// not share values.
+	Files []string `json:"files"`
+	// MinLength is the length a string, or the digits of a number, must
+	// reach before it is compared.
+	MinLength int `json:"min-length"`
+	// Tests checks literals in test files too.
+	Tests bool `json:"tests"`
+}
+
+// namedConstant is a constant whose value can be compared.
+type namedConstant struct {
+	object *types.Const
+	ident  *ast.Ident
+}
+
+// Detects constants of the const files that repeat the literal value of
+// another constant of the same type, and string or number literals of the
+// module equal to an exported package-level constant. Constants defined from
+// other constants, and values of numeric enum types, are not compared, nor
+// are the keys of message catalogs, which spell out the keys looked up.
+func DetectDuplicateConstants(pass *Pass) ([]Finding, error) {
+	options := duplicateConstantsOptions{Files: []string{"config/const.go", "config/Const.go"}, MinLength: 3}
+	if err := pass.DecodeOptions(&options); err != nil {
+		return nil, err
+	}
+	program := pass.Program
+	info := program.TypesInfo()
+	enums := findEnums(program)
+	comparable := func(value constant.Value) bool {
+		return value != nil && len(valueText(value)) >= options.MinLength
+	}
+
+	// Constants spelled out as literals, indexed by value
+	byValue := make(map[string][]namedConstant)
+	constFiles := make(map[string]bool)
+	for _, name := range options.Files {
+		constFiles[filepath.Join(program.Root, name)] = true
+	}
+	// The first constant with each typed value, across all the const files
+	seen := make(map[string]namedConstant)
+	var findings []Finding
+	for _, file := range program.Files {
+		if !file.Checked || file.IsTest {
+			continue
+		}
+		for _, declared := range ExtractConstsFromFile(program, file) {
+			object, ok := info.Defs[declared.ident].(*types.Const)
+			if !ok || !definedByLiteral(declared) || !comparable(object.Val()) {
+				continue
+			}
+			if isNumericEnum(enums, object) {
+				continue
+			}
+			named := namedConstant{object: object, ident: declared.ident}
+			if object.Exported() && !declared.Local {
+				key := valueKey(object.Val())
+				byValue[key] = append(byValue[key], named)
+			}
+			if !constFiles[file.Path] || declared.Local {
+				continue
+			}
+			key := object.Type().String() + " " + object.Val().ExactString()
+			if first, found := seen[key]; found {
+				finding := NewFinding(program.Position(declared.ident.Pos()), program.Position(declared.ident.End()),
+					fmt.Sprintf("Constant '%s' has the same value as '%s' (%s)", declared.Name, constantReference(file, first.object), object.Val().ExactString()))
+				finding.SuggestedFix = fmt.Sprintf("Keep one of them, or define %s = %s if both names are needed", declared.Name, constantReference(file, first.object))
+				findings = append(findings, finding)
+				continue
+			}
+			seen[key] = named
+		}
+	}
+	if len(byValue) == 0 {
+		return findings, nil
+	}
+
+	var files []*File
+	for _, file := range program.Files {
+		if file.Checked && (options.Tests || !file.IsTest) {
+			files = append(files, file)
+		}
+	}
+	// Literals among the keys of the discovered message catalogs
+	catalogKeys := make(map[*ast.BasicLit]bool)
+	catalogs, _ := findCatalogs(program, catalogOptions{})
+	for _, catalog := range catalogs {
+		for _, elt := range catalog.literal.Elts {
+			if keyValue, ok := elt.(*ast.KeyValueExpr); ok {
+				ast.Inspect(keyValue.Key, func(astNode ast.Node) bool {
+					if literal, ok := astNode.(*ast.BasicLit); ok {
+						catalogKeys[literal] = true
+					}
+					return true
+				})
+			}
+		}
+	}
+	literalFindings, err := pass.ForEachFile(files, func(file *File) ([]Finding, error) {
+		var found []Finding
+		ast.Inspect(file.AST, func(astNode ast.Node) bool {
+			switch node := astNode.(type) {
+			case *ast.ImportSpec, *ast.Field:
+				// Import paths and struct tags
+				return false
+			case *ast.ValueSpec:
+				// Exported constants are compared among themselves above
+				for _, name := range node.Names {
+					if object, ok := info.Defs[name].(*types.Const); ok && object.Exported() && object.Parent() == object.Pkg().Scope() {
+						return false
+					}
+				}
+			case *ast.BasicLit:
+				value := info.Types[node].Value
+				if catalogKeys[node] || !comparable(value) {
+					return true
+				}
+				matches := byValue[valueKey(value)]
+				if len(matches) == 0 {
+					return true
+				}
+				var names []string
+				for _, match := range matches {
+					names = append(names, constantReference(file, match.object))
+				}
+				sort.Strings(names)
+				finding := NewFinding(program.Position(node.Pos()), program.Position(node.End()),
+					fmt.Sprintf("Literal %s duplicates constant %s", node.Value, strings.Join(names, " or ")))
+				finding.SuggestedFix = "Use " + names[0]
+				found = append(found, finding)
+			}
+			return true
+		})
+		return found, nil
+	})
+	return append(findings, literalFindings...), err
+}
+
+// definedByLiteral reports whether the constant's own value is a literal,
+// rather than an expression, iota or another constant.
+func definedByLiteral(declared ConstInfo) bool {
+	for idx, name := range declared.spec.Names {
+		if name == declared.ident && idx < len(declared.spec.Values) {
+			_, ok := declared.spec.Values[idx].(*ast.BasicLit)
+			return ok
+		}
+	}
+	return false
+}
+
+// isNumericEnum reports whether the constant is a value of an enum type
+// with a numeric underlying type, whose values coincide with any number.
+func isNumericEnum(enums map[*types.TypeName]*enumType, object *types.Const) bool {
+	typeName := enumTypeName(object.Type())
+	return enums[typeName] != nil && object.Val().Kind() != constant.String
+}
+
+// valueText returns a string's contents or a number's digits.
+func valueText(value constant.Value) string {
+	switch value.Kind() {
+	case constant.String:
+		return constant.StringVal(value)
+	case constant.Int, constant.Float:
+		return strings.TrimPrefix(value.ExactString(), "-")
+	}
+	return ""
+}
+
+// valueKey identifies a value regardless of its type: strings by content and
+// numbers by exact value.
+func valueKey(value constant.Value) string {
+	if value.Kind() == constant.String {
+		return "string " + value.ExactString()
+	}
+	return "number " + constant.ToFloat(value).ExactString()
+}
+
+// constantReference returns how file would refer to a package-level constant.
+func constantReference(file *File, object *types.Const) string {
+	if file.Package != nil && file.Package.Types == object.Pkg() {
+		return object.Name()
+	}
+	return object.Pkg().Name() + "." + object.Name()
+}
@@ -0,0 +1,168 @@
+package detectors
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TextEdit replaces the bytes [Start, End) of a file with NewText. Offsets
+// are byte offsets into the file as it was analyzed.
+type TextEdit struct {
+	File    string `json:"file"`
+	Start   int    `json:"start"`
+	End     int    `json:"end"`
+	NewText string `json:"newText"`
+}
+
+// identEdit replaces an identifier with newName.
+func identEdit(fset *token.FileSet, ident *ast.Ident, newName string) TextEdit {
+	start := fset.Position(ident.Pos())
+	return TextEdit{File: start.Filename, Start: start.Offset, End: fset.Position(ident.End()).Offset, NewText: newName}
+}
+
+// References returns every identifier, declarations included, that denotes
+// the object, in source order. Only type-checked files are covered.
+func (program *Program) References(object types.Object) []*ast.Ident {
+	program.referencesOnce.Do(func() {
+		info := program.TypesInfo()
+		program.references = make(map[types.Object][]*ast.Ident)
+		for ident, def := range info.Defs {
+			if def != nil {
+				program.references[def] = append(program.references[def], ident)
+			}
+		}
+		for ident, use := range info.Uses {
+			program.references[use] = append(program.references[use], ident)
+		}
+		for _, idents := range program.references {
+			sort.Slice(idents, func(i, j int) bool { return idents[i].Pos() < idents[j].Pos() })
+		}
+	})
+	return program.references[object]
+}
+
+// renameEdits returns the edits renaming object to newName at every
+// reference, or false when the rename is not provably safe: the new name is
+// a keyword, or it already denotes something at one of the references.
+func renameEdits(program *Program, object types.Object, newName string) ([]TextEdit, bool) {
+	if object == nil || object.Pkg() == nil || token.IsKeyword(newName) || newName == object.Name() {
+		return nil, false
+	}
+	references := program.References(object)
+	if len(references) == 0 {
+		return nil, false
+	}
+	var edits []TextEdit
+	for _, ident := range references {
+		scope := object.Pkg().Scope().Innermost(ident.Pos())
+		if scope == nil {
+			return nil, false
+		}
+		if _, existing := scope.LookupParent(newName, ident.Pos()); existing != nil {
+			return nil, false
+		}
+		edits = append(edits, identEdit(program.Fset, ident, newName))
+	}
+	// LookupParent only sees declarations before each reference, so look for
+	// a later declaration in the object's own scope too
+	if object.Parent() != nil && object.Parent().Lookup(newName) != nil {
+		return nil, false
+	}
+	// Package-level names may also clash with an import of any file of the package
+	if object.Parent() == object.Pkg().Scope() {
+		info := program.TypesInfo()
+		for _, file := range program.Files {
+			if file.Package == nil || file.Package.Types != object.Pkg() {
+				continue
+			}
+			if scope := info.Scopes[file.AST]; scope != nil && scope.Lookup(newName) != nil {
+				return nil, false
+			}
+		}
+	}
+	return edits, true
+}
+
+// lowerName returns name starting with a lower-case letter. A leading
+// acronym is lowered as a whole: "HTTPServer" becomes "httpServer" and
+// "ID" becomes "id".
+func lowerName(name string) string {
+	runes := []rune(name)
+	upper := 0
+	for upper < len(runes) && unicode.IsUpper(runes[upper]) {
+		upper++
+	}
+	if upper > 1 && upper < len(runes) && unicode.IsLower(runes[upper]) {
+		// Keep the capital that starts the next word
+		upper--
+	}
+	for idx := 0; idx < upper; idx++ {
+		runes[idx] = unicode.ToLower(runes[idx])
+	}
+	return string(runes)
+}
+
+// lineStart returns the offset of the beginning of the line holding offset.
+func lineStart(src []byte, offset int) int {
+	for offset > 0 && src[offset-1] != '\n' {
+		offset--
+	}
+	return offset
+}
+
+// lineEnd returns the offset just past the newline ending the line holding
+// offset, or len(src) on the last line.
+func lineEnd(src []byte, offset int) int {
+	for offset < len(src) && src[offset] != '\n' {
+		offset++
+	}
+	if offset < len(src) {
+		offset++
+	}
+	return offset
+}
+
+// deleteLinesEdit deletes the whole lines spanning [start, end). It returns
+// false when other code shares those lines.
+func deleteLinesEdit(path string, src []byte, start, end int) (TextEdit, bool) {
+	from, to := lineStart(src, start), lineEnd(src, end)
+	before := strings.TrimSpace(string(src[from:start]))
+	after := strings.TrimSpace(string(src[end:to]))
+	if before != "" || (after != "" && !strings.HasPrefix(after, "//")) {
+		return TextEdit{}, false
+	}
+	return TextEdit{File: path, Start: from, End: to}, true
+}
+
+// startsWithWord reports whether text starts with word followed by a
+// non-identifier character.
+func startsWithWord(text, word string) bool {
+	if !strings.HasPrefix(text, word) {
+		return false
+	}
+	next, _ := utf8.DecodeRuneInString(text[len(word):])
+	return next == utf8.RuneError || !isIdentRune(next)
+}
+
+// isIdentRune reports whether r may appear in a Go identifier.
+func isIdentRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// trailingIdent returns the identifier characters at the end of text.
+func trailingIdent(text string) string {
+	end := len(text)
+	for end > 0 {
+		r, size := utf8.DecodeLastRuneInString(text[:end])
+		if !isIdentRune(r) {
+			break
+		}
+		end -= size
+	}
+	return text[end:]
+}
@@ -0,0 +1,249 @@
+package detectors
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+func init() {
+	Register(Document(
+		NewDetector(
+			"enum-switch",
+			"Reports switch statements over enum types that miss values and have no default",
+			CategoryCorrectness,
+			SeverityWarning,
+			DetectEnumSwitches,
+		),
+		Explanation{
+			Rationale: `Adding a value to an enum-style type is easy to miss in the switch statements
+that branch on it: without a default clause, the new value silently falls
+through every case. An enum is a named integer or string type declared in
+the module with at least two constants of that type in its package, such as
+a typed iota group. A switch on such a type must list every value, or have
+a default clause that states what happens to the others. When every case
+returns, a return or panic right after the switch counts as its default.`,
+			Bad: `type OrderState int
+
+const (
+	Pending OrderState = iota
+	Paid
+	Shipped
+)
+
+switch state {
+case Pending:
+	notify()
+case Paid:
+	ship()
+}`,
+			Good: `switch state {
+case Pending:
+	notify()
+case Paid:
+	ship()
+case Shipped:
+	// nothing left to do
+}`,
+		},
+	))
+}
+
+// enumType is a named type of the module used as an enumeration.
+type enumType struct {
+	Type *types.TypeName
+	// Values are the constants of the type, in declaration order.
+	Values []*types.Const
+}
+
+// findEnums indexes the enum types declared in the type-checked packages:
+// named types with an integer or string underlying type and at least two
+// package-level constants of that type in their own package.
+func findEnums(program *Program) map[*types.TypeName]*enumType {
+	program.TypesInfo()
+	enums := make(map[*types.TypeName]*enumType)
+	seen := make(map[*types.Package]bool)
+	for _, file := range program.Files {
+		if !file.Checked || file.Package.Types == nil || seen[file.Package.Types] {
+			continue
+		}
+		seen[file.Package.Types] = true
+		scope := file.Package.Types.Scope()
+		for _, name := range scope.Names() {
+			value, ok := scope.Lookup(name).(*types.Const)
+			if !ok {
+				continue
+			}
+			typeName := enumTypeName(value.Type())
+			if typeName == nil || typeName.Pkg() != value.Pkg() {
+				continue
+			}
+			enum := enums[typeName]
+			if enum == nil {
+				enum = &enumType{Type: typeName}
+				enums[typeName] = enum
+			}
+			enum.Values = append(enum.Values, value)
+		}
+	}
+	for typeName, enum := range enums {
+		if len(enum.Values) < 2 {
+			delete(enums, typeName)
+			continue
+		}
+		sort.Slice(enum.Values, func(i, j int) bool { return enum.Values[i].Pos() < enum.Values[j].Pos() })
+	}
+	return enums
+}
+
+// enumTypeName returns the name of a named, non-generic type with an integer
+// or string underlying type, or nil.
+func enumTypeName(typ types.Type) *types.TypeName {
+	named, ok := types.Unalias(typ).(*types.Named)
+	if !ok || named.TypeArgs() != nil {
+		return nil
+	}
+	basic, ok := named.Underlying().(*types.Basic)
+	if !ok || basic.Info()&(types.IsInteger|types.IsString) == 0 {
+		return nil
+	}
+	return named.Obj()
+}
+
+// Detects switch statements on enum values that have no default clause and
+// leave some values out. Values are compared by constant value, so a case
+// naming an alias of a value covers it. Switches with a case that is not a
+// constant are not checked, nor are those whose every case ends in a
+// terminating statement and which are directly followed by one, such as
+// "return y": that statement is only reached by the values left out.
+func DetectEnumSwitches(pass *Pass) ([]Finding, error) {
+	program := pass.Program
+	enums := findEnums(program)
+	if len(enums) == 0 {
+		return nil, nil
+	}
+	info := program.TypesInfo()
+
+	var files []*File
+	for _, file := range program.Files {
+		if file.Checked {
+			files = append(files, file)
+		}
+	}
+	return pass.ForEachFile(files, func(file *File) ([]Finding, error) {
+		var findings []Finding
+		// Switches whose cases all terminate, followed by a terminating
+		// fallback; statement lists are visited before the switches in them
+		handled := make(map[*ast.SwitchStmt]bool)
+		ast.Inspect(file.AST, func(astNode ast.Node) bool {
+			var list []ast.Stmt
+			switch node := astNode.(type) {
+			case *ast.BlockStmt:
+				list = node.List
+			case *ast.CaseClause:
+				list = node.Body
+			case *ast.CommClause:
+				list = node.Body
+			}
+			for idx := 0; idx+1 < len(list); idx++ {
+				if switchStmt, ok := list[idx].(*ast.SwitchStmt); ok && casesTerminate(info, switchStmt) && isTerminating(info, list[idx+1]) {
+					handled[switchStmt] = true
+				}
+			}
+
+			switchStmt, ok := astNode.(*ast.SwitchStmt)
+			if !ok || switchStmt.Tag == nil || handled[switchStmt] {
+				return true
+			}
+			typeName := enumTypeName(info.TypeOf(switchStmt.Tag))
+			enum := enums[typeName]
+			if enum == nil {
+				return true
+			}
+			missing, checkable := missingEnumValues(info, switchStmt, enum)
+			if !checkable || len(missing) == 0 {
+				return true
+			}
+			finding := NewFinding(program.Position(switchStmt.Pos()), program.Position(switchStmt.Body.Lbrace),
+				fmt.Sprintf("switch on %s is missing %s", typeName.Name(), strings.Join(missing, ", ")))
+			finding.SuggestedFix = "Add a case for each missing value, or a default clause"
+			findings = append(findings, finding)
+			return true
+		})
+		return findings, nil
+	})
+}
+
+// isTerminating reports whether control never continues past stmt: a
+// return, a goto, a call to panic, or a block ending in one of them.
+func isTerminating(info *types.Info, stmt ast.Stmt) bool {
+	switch stmt := stmt.(type) {
+	case *ast.ReturnStmt:
+		return true
+	case *ast.BranchStmt:
+		return stmt.Tok == token.GOTO
+	case *ast.ExprStmt:
+		call, ok := ast.Unparen(stmt.X).(*ast.CallExpr)
+		if !ok {
+			return false
+		}
+		ident, ok := ast.Unparen(call.Fun).(*ast.Ident)
+		if !ok {
+			return false
+		}
+		_, isBuiltin := info.Uses[ident].(*types.Builtin)
+		return isBuiltin && ident.Name == "panic"
+	case *ast.BlockStmt:
+		return len(stmt.List) > 0 && isTerminating(info, stmt.List[len(stmt.List)-1])
+	}
+	return false
+}
+
+// casesTerminate reports whether every case clause of the switch ends in a
+// terminating statement, so that no case reaches the code after it.
+func casesTerminate(info *types.Info, switchStmt *ast.SwitchStmt) bool {
+	for _, stmt := range switchStmt.Body.List {
+		body := stmt.(*ast.CaseClause).Body
+		if len(body) == 0 || !isTerminating(info, body[len(body)-1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// missingEnumValues returns the names of the enum values no case of the
+// switch covers. checkable is false when the switch has a default clause
+// or a case whose value is not a constant.
+func missingEnumValues(info *types.Info, switchStmt *ast.SwitchStmt, enum *enumType) (missing []string, checkable bool) {
+	var covered []constant.Value
+	for _, stmt := range switchStmt.Body.List {
+		clause := stmt.(*ast.CaseClause)
+		if clause.List == nil {
+			return nil, false
+		}
+		for _, expr := range clause.List {
+			value := info.Types[expr].Value
+			if value == nil {
+				return nil, false
+			}
+			covered = append(covered, value)
+		}
+	}
+	for _, enumValue := range enum.Values {
+		found := false
+		for _, value := range covered {
+			if constant.Compare(enumValue.Val(), token.EQL, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, enumValue.Name())
+		}
+	}
+	return missing, true
+}
@@ -0,0 +1,107 @@
+package detectors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"sort"
+)
+
+// Finding is a single problem reported by a detector.
+type Finding struct {
+	// RuleID is the name of the detector that produced the finding.
+	RuleID   string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	File     string   `json:"file"`
+	Line     int      `json:"line"`
+	Column   int      `json:"column"`
+	// EndLine and EndColumn are zero when the end of the range is unknown.
+	EndLine      int    `json:"endLine,omitempty"`
+	EndColumn    int    `json:"endColumn,omitempty"`
+	Message      string `json:"message"`
+	SuggestedFix string `json:"suggestedFix,omitempty"`
+	// Edits mechanically fix the finding when applied together. They are
+	// only set when the fix is known to be safe.
+	Edits []TextEdit `json:"edits,omitempty"`
+}
+
+// NewFinding creates a finding located at the given start and end positions.
+// The end position may be the zero value.
+func NewFinding(start, end token.Position, message string) Finding {
+	finding := Finding{
+		File:    start.Filename,
+		Line:    start.Line,
+		Column:  start.Column,
+		Message: message,
+	}
+	if end.IsValid() {
+		finding.EndLine = end.Line
+		finding.EndColumn = end.Column
+	}
+	return finding
+}
+
+// Pass carries the inputs of a single detector run.
+type Pass struct {
+	// Root is the absolute path of the directory being analyzed.
+	Root string
+	// Program is the parsed project, shared by every detector of the run.
+	Program *Program
+	// Options are the detector's settings from the project configuration.
+	Options map[string]any
+	// pool bounds the file-level work started through ForEachFile.
+	pool workerPool
+	// rule is the name of the running detector.
+	rule string
+	// suppressions are the directives of the run; nil outside Run.
+	suppressions *suppressionIndex
+}
+
+// Suppressed reports whether a suppression directive silences a finding of
+// the detector at position. Detectors that summarize several places in one
+// finding use it to leave out the places the user suppressed.
+func (pass *Pass) Suppressed(position token.Position) bool {
+	if pass.suppressions == nil {
+		return false
+	}
+	return pass.suppressions.suppressed(Finding{RuleID: pass.rule, File: position.Filename, Line: position.Line})
+}
+
+// DecodeOptions decodes the detector's options into target, which should hold
+// the defaults beforehand. Unknown option names are reported as errors.
+func (pass *Pass) DecodeOptions(target any) error {
+	if len(pass.Options) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(pass.Options)
+	if err != nil {
+		return fmt.Errorf("invalid options: %v", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(target); err != nil {
+		return fmt.Errorf("invalid options: %v", err)
+	}
+	return nil
+}
+
+// SortFindings orders findings by file, position, rule and message.
+func SortFindings(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		left, right := findings[i], findings[j]
+		if left.File != right.File {
+			return left.File < right.File
+		}
+		if left.Line != right.Line {
+			return left.Line < right.Line
+		}
+		if left.Column != right.Column {
+			return left.Column < right.Column
+		}
+		if left.RuleID != right.RuleID {
+			return left.RuleID < right.RuleID
+		}
+		return left.Message < right.Message
+	})
+}
@@ -0,0 +1,125 @@
+package detectors
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// goModFile holds the parts of a go.mod file the detectors need.
+type goModFile struct {
+	// Path is the location of the go.mod file.
+	Path string
+	// Require maps each required module to its version.
+	Require map[string]string
+	// Replace maps replaced modules to their new version, or to "" when
+	// they are replaced by a directory and have no version.
+	Replace map[string]string
+}
+
+// parseGoMod reads the require and replace directives of a go.mod file,
+// in their single-line and block forms.
+func parseGoMod(path string, data []byte) *goModFile {
+	goMod := &goModFile{
+		Path:    path,
+		Require: make(map[string]string),
+		Replace: make(map[string]string),
+	}
+	block := ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "//")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		directive := block
+		switch {
+		case block != "" && fields[0] == ")":
+			block = ""
+			continue
+		case block != "":
+		case len(fields) == 2 && fields[1] == "(":
+			block = fields[0]
+			continue
+		default:
+			// A single-line directive
+			directive, fields = fields[0], fields[1:]
+		}
+		for idx := range fields {
+			fields[idx] = strings.Trim(fields[idx], `"`)
+		}
+		switch directive {
+		case "require":
+			if len(fields) >= 2 {
+				goMod.Require[fields[0]] = fields[1]
+			}
+		case "replace":
+			// "old [version] => new [version]"; only replacements of every
+			// version of a module change the version in use
+			if len(fields) >= 3 && fields[1] == "=>" {
+				goMod.Replace[fields[0]] = ""
+				if len(fields) == 4 {
+					goMod.Replace[fields[0]] = fields[3]
+				}
+			}
+		}
+	}
+	return goMod
+}
+
+// Version returns the version of the module that provides importPath: the
+// longest required module path that is a prefix of it, with replacements
+// applied. ok is false when no requirement covers the import or when the
+// module is replaced by a directory.
+func (goMod *goModFile) Version(importPath string) (module, version string, ok bool) {
+	for required, requiredVersion := range goMod.Require {
+		if (importPath == required || strings.HasPrefix(importPath, required+"/")) && len(required) > len(module) {
+			module, version = required, requiredVersion
+		}
+	}
+	if module == "" {
+		return "", "", false
+	}
+	if replaced, found := goMod.Replace[module]; found {
+		version = replaced
+	}
+	return module, version, version != ""
+}
+
+// goModCache reads each go.mod file once per detector run. Files are
+// checked in parallel, so access is guarded by the mutex.
+type goModCache struct {
+	mutex sync.Mutex
+	// byDir maps directories to the go.mod governing them, or nil.
+	byDir map[string]*goModFile
+}
+
+// lookup returns the go.mod governing dir, searching parent directories,
+// or nil when there is none.
+func (cache *goModCache) lookup(dir string) *goModFile {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if cache.byDir == nil {
+		cache.byDir = make(map[string]*goModFile)
+	}
+	return cache.find(dir)
+}
+
+func (cache *goModCache) find(dir string) *goModFile {
+	if goMod, ok := cache.byDir[dir]; ok {
+		return goMod
+	}
+	var goMod *goModFile
+	path := filepath.Join(dir, "go.mod")
+	if data, err := os.ReadFile(path); err == nil {
+		goMod = parseGoMod(path, data)
+	} else if parent := filepath.Dir(dir); parent != dir {
+		goMod = cache.find(parent)
+	}
+	cache.byDir[dir] = goMod
+	return goMod
+}
@@ -0,0 +1,118 @@
+package detectors
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseGoMod(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		wantRequire map[string]string
+		wantReplace map[string]string
+	}{
+		{
+			name: "single-line directives",
+			data: `module example.com/app
+
+go 1.22
+
+require github.com/pkg/errors v0.9.1
+replace github.com/pkg/errors => github.com/fork/errors v0.9.2
+`,
+			wantRequire: map[string]string{"github.com/pkg/errors": "v0.9.1"},
+			wantReplace: map[string]string{"github.com/pkg/errors": "v0.9.2"},
+		},
+		{
+			name: "blocks with comments",
+			data: `module example.com/app
+
+require (
+	// logging
+	github.com/sirupsen/logrus v1.9.0 // indirect
+	"golang.org/x/text" v0.14.0
+
+)
+
+replace (
+	github.com/sirupsen/logrus => ../logrus
+	golang.org/x/text v0.14.0 => golang.org/x/text v0.15.0
+)
+`,
+			wantRequire: map[string]string{
+				"github.com/sirupsen/logrus": "v1.9.0",
+				"golang.org/x/text":          "v0.14.0",
+			},
+			wantReplace: map[string]string{"github.com/sirupsen/logrus": ""},
+		},
+		{
+			name: "several require blocks",
+			data: `require (
+	a.example/one v1.0.0
+)
+require (
+	b.example/two v2.0.0-20240101120000-abcdef123456
+)
+`,
+			wantRequire: map[string]string{
+				"a.example/one": "v1.0.0",
+				"b.example/two": "v2.0.0-20240101120000-abcdef123456",
+			},
+			wantReplace: map[string]string{},
+		},
+		{
+			name:        "no requirements",
+			data:        "module example.com/app\n\ngo 1.22\n",
+			wantRequire: map[string]string{},
+			wantReplace: map[string]string{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			goMod := parseGoMod("go.mod", []byte(test.data))
+			if !reflect.DeepEqual(goMod.Require, test.wantRequire) {
+				t.Errorf("Require = %v, want %v", goMod.Require, test.wantRequire)
+			}
+			if !reflect.DeepEqual(goMod.Replace, test.wantReplace) {
+				t.Errorf("Replace = %v, want %v", goMod.Replace, test.wantReplace)
+			}
+		})
+	}
+}
+
+func TestGoModVersion(t *testing.T) {
+	goMod := parseGoMod("go.mod", []byte(`require (
+	github.com/aws/aws-sdk-go v1.44.0
+	github.com/aws/aws-sdk-go/service/s3 v1.50.0
+	github.com/local/tool v1.0.0
+	github.com/old/lib v1.0.0
+)
+
+replace github.com/local/tool => ./tool
+replace github.com/old/lib => github.com/new/lib v1.2.0
+`))
+	tests := []struct {
+		importPath  string
+		wantModule  string
+		wantVersion string
+		wantOK      bool
+	}{
+		{"github.com/aws/aws-sdk-go", "github.com/aws/aws-sdk-go", "v1.44.0", true},
+		{"github.com/aws/aws-sdk-go/aws/session", "github.com/aws/aws-sdk-go", "v1.44.0", true},
+		{"github.com/aws/aws-sdk-go/service/s3/s3manager", "github.com/aws/aws-sdk-go/service/s3", "v1.50.0", true},
+		{"github.com/aws/aws-sdk-go-v2", "", "", false},
+		{"github.com/local/tool/cmd", "github.com/local/tool", "", false},
+		{"github.com/old/lib", "github.com/old/lib", "v1.2.0", true},
+		{"fmt", "", "", false},
+	}
+	for _, test := range tests {
+		t.Run(test.importPath, func(t *testing.T) {
+			module, version, ok := goMod.Version(test.importPath)
+			if module != test.wantModule || version != test.wantVersion || ok != test.wantOK {
+				t.Errorf("Version(%q) = %q, %q, %v, want %q, %q, %v",
+					test.importPath, module, version, ok, test.wantModule, test.wantVersion, test.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,489 @@
+package detectors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	Register(Document(
+		NewDetector(
+			"message-locales",
+			"Reports translations that miss keys, add keys, are empty or change placeholders",
+			CategoryMessages,
+			SeverityWarning,
+			DetectMessageLocales,
+		),
+		Explanation{
+			Rationale: `Each locale of a message catalog must translate the same keys as the source
+locale, with the same placeholders: a missing key shows users a blank or
+untranslated message, and a translation that drops a %s or {name} prints
+the wrong text or "%!(EXTRA string=...)". Catalogs are Go maps named per
+locale (MessagesEN, MessagesFR, or Messages in i18n/en and i18n/fr) and
+JSON, YAML or TOML files named after their locale (locales/en.json,
+locales/fr.yaml, messages.fr.toml). Nested keys of files are joined with dots.`,
+			Bad: `// locales/en.json
+{"welcome": "Welcome, {name}!", "bye": "Bye"}
+
+// locales/fr.json
+{"welcome": "Bienvenue !", "bye": ""}`,
+			Good: `// locales/fr.json
+{"welcome": "Bienvenue, {name} !", "bye": "Au revoir"}`,
+		},
+	))
+}
+
+// messageLocalesOptions are the settings of the message-locales detector.
+type messageLocalesOptions struct {
+	catalogOptions
+	// Source is the locale every other locale is compared with.
+	Source string `json:"source"`
+	// Translations are glob patterns, relative to the root, of the JSON, YAML
+	// and TOML translation files.
+	Translations []string `json:"translations"`
+}
+
+// localeCatalog holds the messages of one locale.
+type localeCatalog struct {
+	// Locale is normalized to lower case with underscores, such as "pt_br".
+	Locale string
+	// Group identifies the catalogs that translate each other.
+	Group string
+	// Name is how findings refer to the catalog.
+	Name string
+	// Position is where keys missing from the catalog are reported.
+	Position token.Position
+	Entries  []MessageKey
+	// Base is set for a Go map without a locale suffix, such as Messages
+	// next to MessagesFR, which holds the source locale.
+	Base bool
+	// byDirectory is set when the locale was taken from the directory of
+	// the package, which may only look like a locale tag.
+	byDirectory bool
+}
+
+// localeTag matches locale names such as "en", "fr", "pt_BR" or "zh-Hant".
+var localeTag = regexp.MustCompile(`^[a-zA-Z]{2,3}([_-][a-zA-Z0-9]{2,4})?$`)
+
+// localeSuffix matches catalog variables named after their locale:
+// MessagesEN, MessagesFr, Messages_pt_BR.
+var localeSuffix = regexp.MustCompile(`^(.*[a-z])(?:([A-Z][A-Za-z])|_([a-z]{2,3}(?:_[A-Za-z]{2,4})?))$`)
+
+// placeholderPattern matches printf verbs and {name}, {{name}} or {{.Name}}
+// placeholders.
+var placeholderPattern = regexp.MustCompile(`%(?:\[\d+\])?[-+# 0]*(?:\d+|\*)?(?:\.(?:\d+|\*))?[a-zA-Z]|\{\{?\s*\.?[\w.]+\s*\}?\}`)
+
+// Detects inconsistencies between the locales of each message catalog: keys
+// of the source locale missing from a translation, keys only a translation
+// has, empty messages, and translations whose placeholders differ from the
+// source. A Go map without a locale suffix, such as Messages next to
+// MessagesFR, holds the source locale of its group. Groups without the
+// source locale are reported, except those found by directory name, so
+// directories that merely look like locale tags, such as api/, are left
+// alone.
+func DetectMessageLocales(pass *Pass) ([]Finding, error) {
+	options := messageLocalesOptions{
+		Source:       "en",
+		Translations: []string{"locales/*", "i18n/*", "translations/*", "lang/*"},
+	}
+	if err := pass.DecodeOptions(&options); err != nil {
+		return nil, err
+	}
+	source := normalizeLocale(options.Source)
+	catalogs, err := goLocaleCatalogs(pass.Program, options.catalogOptions, source)
+	fileCatalogs, fileErr := fileLocaleCatalogs(pass.Root, options.Translations)
+	catalogs = append(catalogs, fileCatalogs...)
+	if err == nil {
+		err = fileErr
+	}
+
+	groups := make(map[string][]*localeCatalog)
+	for _, catalog := range catalogs {
+		groups[catalog.Group] = append(groups[catalog.Group], catalog)
+	}
+	var findings []Finding
+	for _, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+		// A catalog named after the source locale wins over a base catalog
+		var sourceCatalog *localeCatalog
+		for _, catalog := range group {
+			if catalog.Locale == source && (sourceCatalog == nil || sourceCatalog.Base) {
+				sourceCatalog = catalog
+			}
+		}
+		if sourceCatalog == nil {
+			if !group[0].byDirectory {
+				findings = append(findings, missingSourceFinding(group, source))
+			}
+			continue
+		}
+		for _, catalog := range group {
+			findings = append(findings, emptyMessageFindings(catalog)...)
+			if catalog != sourceCatalog {
+				findings = append(findings, compareLocales(sourceCatalog, catalog)...)
+			}
+		}
+	}
+	return findings, err
+}
+
+// normalizeLocale lowers a locale tag and joins its parts with an underscore.
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(locale, "-", "_"))
+}
+
+// goLocaleCatalogs returns the Go message catalogs whose locale is known
+// from the variable name or from the directory of the package. A catalog
+// without a locale suffix in a package that also declares suffixed ones,
+// such as Messages next to MessagesFR, holds the source locale.
+func goLocaleCatalogs(program *Program, options catalogOptions, source string) ([]*localeCatalog, error) {
+	catalogs, err := findCatalogs(program, options)
+	suffixed := make(map[string]bool)
+	for _, catalog := range catalogs {
+		if match := localeSuffix.FindStringSubmatch(catalog.Name); match != nil {
+			suffixed[catalog.Package+"."+match[1]] = true
+		}
+	}
+	var locales []*localeCatalog
+	for _, catalog := range catalogs {
+		locale := &localeCatalog{
+			Name:     catalog.String(),
+			Position: program.Position(catalog.literal.Pos()),
+			Entries:  catalog.Keys,
+		}
+		dir := filepath.Dir(catalog.File.Path)
+		if match := localeSuffix.FindStringSubmatch(catalog.Name); match != nil {
+			locale.Locale = normalizeLocale(match[2] + match[3])
+			locale.Group = catalog.Package + "." + match[1]
+		} else if group := catalog.Package + "." + catalog.Name; suffixed[group] {
+			locale.Locale, locale.Group, locale.Base = source, group, true
+		} else if localeTag.MatchString(filepath.Base(dir)) {
+			locale.Locale = normalizeLocale(filepath.Base(dir))
+			locale.Group = filepath.Dir(dir) + "." + catalog.Name
+			locale.byDirectory = true
+		} else {
+			continue
+		}
+		locales = append(locales, locale)
+	}
+	return locales, err
+}
+
+// fileLocaleCatalogs reads the translation files matched by the patterns.
+// A file is a translation when its name, or the last dotted part of its
+// name, is a locale tag: en.json, messages.fr.yaml. Files that cannot be
+// read are left out, and the first error is returned.
+func fileLocaleCatalogs(root string, patterns []string) ([]*localeCatalog, error) {
+	var catalogs []*localeCatalog
+	var firstErr error
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		paths, err := filepath.Glob(filepath.Join(root, filepath.FromSlash(pattern)))
+		if err != nil {
+			return catalogs, fmt.Errorf("invalid options: translations: %v", err)
+		}
+		for _, file := range paths {
+			extension := strings.ToLower(filepath.Ext(file))
+			if seen[file] || (extension != ".json" && extension != ".yaml" && extension != ".yml" && extension != ".toml") {
+				continue
+			}
+			seen[file] = true
+			base := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+			prefix, locale := "", base
+			if dot := strings.LastIndex(base, "."); dot >= 0 {
+				prefix, locale = base[:dot], base[dot+1:]
+			}
+			if !localeTag.MatchString(locale) {
+				continue
+			}
+			data, err := os.ReadFile(file)
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("error reading %s: %v", file, err)
+				}
+				continue
+			}
+			entries, err := readTranslations(file, extension, data)
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("error parsing %s: %v", file, err)
+				}
+				continue
+			}
+			name, _ := filepath.Rel(root, file)
+			catalogs = append(catalogs, &localeCatalog{
+				Locale:   normalizeLocale(locale),
+				Group:    filepath.Join(filepath.Dir(file), prefix),
+				Name:     filepath.ToSlash(name),
+				Position: token.Position{Filename: file, Line: 1, Column: 1},
+				Entries:  entries,
+			})
+		}
+	}
+	return catalogs, firstErr
+}
+
+// readTranslations flattens a translation file into dotted keys.
+func readTranslations(file, extension string, data []byte) ([]MessageKey, error) {
+	switch extension {
+	case ".json":
+		return readJSONTranslations(file, data)
+	case ".toml":
+		var tree map[string]any
+		if _, err := toml.Decode(string(data), &tree); err != nil {
+			return nil, err
+		}
+		var entries []MessageKey
+		flattenTranslations(tree, "", func(key, value string) {
+			entries = append(entries, MessageKey{Key: key, Value: value, Position: tomlKeyPosition(file, data, key), valueKnown: true})
+		})
+		return entries, nil
+	}
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	var entries []MessageKey
+	if len(document.Content) > 0 {
+		walkYAMLTranslations(file, document.Content[0], "", &entries)
+	}
+	return entries, nil
+}
+
+// flattenTranslations calls add for every leaf of a decoded tree.
+func flattenTranslations(tree any, prefix string, add func(key, value string)) {
+	switch node := tree.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(node))
+		for key := range node {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			flattenTranslations(node[key], joinKey(prefix, key), add)
+		}
+	case []any:
+		for idx, item := range node {
+			flattenTranslations(item, joinKey(prefix, strconv.Itoa(idx)), add)
+		}
+	case string:
+		add(prefix, node)
+	default:
+		add(prefix, fmt.Sprint(node))
+	}
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// readJSONTranslations walks the tokens of a JSON document, keeping the line
+// of every key.
+func readJSONTranslations(file string, data []byte) ([]MessageKey, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var entries []MessageKey
+	var walk func(prefix string) error
+	walk = func(prefix string) error {
+		start := decoder.InputOffset()
+		tok, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'):
+			for decoder.More() {
+				keyToken, err := decoder.Token()
+				if err != nil {
+					return err
+				}
+				key, _ := keyToken.(string)
+				if err := walk(joinKey(prefix, key)); err != nil {
+					return err
+				}
+			}
+			_, err = decoder.Token()
+			return err
+		case json.Delim('['):
+			for idx := 0; decoder.More(); idx++ {
+				if err := walk(joinKey(prefix, strconv.Itoa(idx))); err != nil {
+					return err
+				}
+			}
+			_, err = decoder.Token()
+			return err
+		}
+		value := fmt.Sprint(tok)
+		if text, ok := tok.(string); ok {
+			value = text
+		}
+		entries = append(entries, MessageKey{Key: prefix, Value: value, Position: offsetPosition(file, data, int(start)), valueKnown: true})
+		return nil
+	}
+	if err := walk(""); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// offsetPosition returns the position of the first token after offset.
+func offsetPosition(file string, data []byte, offset int) token.Position {
+	for offset < len(data) && strings.ContainsRune(" \t\r\n:,", rune(data[offset])) {
+		offset++
+	}
+	line := 1 + bytes.Count(data[:offset], []byte("\n"))
+	column := offset - bytes.LastIndexByte(data[:offset], '\n')
+	return token.Position{Filename: file, Offset: offset, Line: line, Column: column}
+}
+
+// walkYAMLTranslations flattens a YAML node, keeping the position of every key.
+func walkYAMLTranslations(file string, node *yaml.Node, prefix string, entries *[]MessageKey) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for idx := 0; idx+1 < len(node.Content); idx += 2 {
+			key, value := node.Content[idx], node.Content[idx+1]
+			if value.Kind == yaml.ScalarNode {
+				*entries = append(*entries, MessageKey{
+					Key:        joinKey(prefix, key.Value),
+					Value:      value.Value,
+					Position:   token.Position{Filename: file, Line: key.Line, Column: key.Column},
+					valueKnown: true,
+				})
+				continue
+			}
+			walkYAMLTranslations(file, value, joinKey(prefix, key.Value), entries)
+		}
+	case yaml.SequenceNode:
+		for idx, item := range node.Content {
+			if item.Kind == yaml.ScalarNode {
+				*entries = append(*entries, MessageKey{
+					Key:        joinKey(prefix, strconv.Itoa(idx)),
+					Value:      item.Value,
+					Position:   token.Position{Filename: file, Line: item.Line, Column: item.Column},
+					valueKnown: true,
+				})
+				continue
+			}
+			walkYAMLTranslations(file, item, joinKey(prefix, strconv.Itoa(idx)), entries)
+		}
+	case yaml.AliasNode:
+		walkYAMLTranslations(file, node.Alias, prefix, entries)
+	}
+}
+
+// tomlKeyPosition returns the line assigning the last part of a dotted
+// key. The TOML decoder does not report positions, so the first matching
+// assignment is used, or the first line.
+func tomlKeyPosition(file string, data []byte, key string) token.Position {
+	name := path.Ext("." + key)[1:]
+	for idx, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " \t")
+		rest, ok := strings.CutPrefix(strings.Trim(trimmed, `"'`), name)
+		if !ok {
+			rest, ok = strings.CutPrefix(trimmed, strconv.Quote(name))
+		}
+		if ok && strings.HasPrefix(strings.TrimLeft(strings.TrimLeft(rest, `"'`), " \t"), "=") {
+			return token.Position{Filename: file, Line: idx + 1, Column: len(line) - len(trimmed) + 1}
+		}
+	}
+	return token.Position{Filename: file, Line: 1, Column: 1}
+}
+
+// missingSourceFinding reports a group of catalogs that has no catalog of the
+// source locale to compare the others with.
+func missingSourceFinding(group []*localeCatalog, source string) Finding {
+	sort.Slice(group, func(i, j int) bool { return group[i].Name < group[j].Name })
+	var names []string
+	for _, catalog := range group {
+		names = append(names, catalog.Name)
+	}
+	finding := NewFinding(group[0].Position, token.Position{},
+		fmt.Sprintf("Locales %s have no source locale %s to compare with", strings.Join(names, ", "), source))
+	finding.SuggestedFix = fmt.Sprintf("Add the %s catalog, or set the source option to the locale the others translate", source)
+	return finding
+}
+
+// emptyMessageFindings reports the empty messages of a catalog.
+func emptyMessageFindings(catalog *localeCatalog) []Finding {
+	var findings []Finding
+	for _, entry := range catalog.Entries {
+		if entry.valueKnown && strings.TrimSpace(entry.Value) == "" {
+			findings = append(findings, NewFinding(entry.Position, token.Position{},
+				fmt.Sprintf("Message '%s' is empty in locale %s (%s)", entry.Key, catalog.Locale, catalog.Name)))
+		}
+	}
+	return findings
+}
+
+// compareLocales reports the keys a translation misses or adds, and the
+// messages whose placeholders differ from the source.
+func compareLocales(source, translation *localeCatalog) []Finding {
+	var findings []Finding
+	translated := make(map[string]MessageKey)
+	for _, entry := range translation.Entries {
+		translated[entry.Key] = entry
+	}
+	original := make(map[string]bool)
+	for _, entry := range source.Entries {
+		original[entry.Key] = true
+		match, found := translated[entry.Key]
+		if !found {
+			finding := NewFinding(translation.Position, token.Position{},
+				fmt.Sprintf("Key '%s' of locale %s is missing from locale %s (%s)", entry.Key, source.Locale, translation.Locale, translation.Name))
+			finding.SuggestedFix = fmt.Sprintf("Translate %q", entry.Value)
+			findings = append(findings, finding)
+			continue
+		}
+		if !entry.valueKnown || !match.valueKnown || strings.TrimSpace(match.Value) == "" {
+			continue
+		}
+		want, got := placeholders(entry.Value), placeholders(match.Value)
+		if strings.Join(want, " ") != strings.Join(got, " ") {
+			findings = append(findings, NewFinding(match.Position, token.Position{},
+				fmt.Sprintf("Placeholders of '%s' differ in locale %s: [%s] in %s, [%s] in %s",
+					entry.Key, translation.Locale, strings.Join(want, " "), source.Locale, strings.Join(got, " "), translation.Locale)))
+		}
+	}
+	for _, entry := range translation.Entries {
+		if !original[entry.Key] {
+			finding := NewFinding(entry.Position, token.Position{},
+				fmt.Sprintf("Key '%s' of locale %s is not in source locale %s (%s)", entry.Key, translation.Locale, source.Locale, source.Name))
+			finding.SuggestedFix = "Add the key to the source locale, or remove it"
+			findings = append(findings, finding)
+		}
+	}
+	return findings
+}
+
+// placeholders returns the placeholders of a message: printf verbs in their
+// order, which matters for their arguments, followed by the named ones
+// sorted, since translations may reorder them.
+func placeholders(message string) []string {
+	var verbs, named []string
+	for _, match := range placeholderPattern.FindAllString(strings.ReplaceAll(message, "%%", ""), -1) {
+		if strings.HasPrefix(match, "%") {
+			verbs = append(verbs, match)
+		} else {
+			named = append(named, strings.Join(strings.Fields(match), ""))
+		}
+	}
+	sort.Strings(named)
+	return append(verbs, named...)
+}
@@ -0,0 +1,110 @@
+package detectors
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Aadi-IRON/agni/config"
+)
+
+func TestDetectMessageLocales(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		options map[string]any
+		want    []string
+	}{
+		{
+			name: "maps named after their locale",
+			files: map[string]string{"messages.go": `package app
+
+var MessagesEN = map[string]string{
+	"welcome": "Welcome, %s!",
+	"bye":     "Bye",
+	"help":    "Help",
+}
+
+var MessagesFR = map[string]string{
+	"welcome": "Bienvenue !",
+	"bye":     "",
+	"extra":   "En plus",
+}
+`},
+			want: []string{
+				"messages.go:9 warning: Key 'help' of locale en is missing from locale fr (app.MessagesFR)",
+				"messages.go:10 warning: Placeholders of 'welcome' differ in locale fr: [%s] in en, [] in fr",
+				"messages.go:11 warning: Message 'bye' is empty in locale fr (app.MessagesFR)",
+				"messages.go:12 warning: Key 'extra' of locale fr is not in source locale en (app.MessagesEN)",
+			},
+		},
+		{
+			name: "base map holds the source locale",
+			files: map[string]string{"messages.go": `package app
+
+var Messages = map[string]string{
+	"welcome": "Welcome, {name}!",
+	"bye":     "Bye",
+}
+
+var MessagesDe = map[string]string{
+	"welcome": "Willkommen, {name}!",
+}
+`},
+			want: []string{"messages.go:8 warning: Key 'bye' of locale en is missing from locale de (app.MessagesDe)"},
+		},
+		{
+			name: "group without the source locale",
+			files: map[string]string{"messages.go": `package app
+
+var MessagesFR = map[string]string{"bye": "Au revoir"}
+
+var MessagesDE = map[string]string{"bye": "Tschüss"}
+`},
+			want: []string{"messages.go:5 warning: Locales app.MessagesDE, app.MessagesFR have no source locale en to compare with"},
+		},
+		{
+			name: "maps in locale directories",
+			files: map[string]string{
+				"i18n/en/messages.go": "package en\n\nvar Messages = map[string]string{\"bye\": \"Bye\", \"help\": \"Help\"}\n",
+				"i18n/fr/messages.go": "package fr\n\nvar Messages = map[string]string{\"bye\": \"Au revoir\"}\n",
+				"api/messages.go":     "package api\n\nvar Messages = map[string]string{\"bye\": \"Bye\"}\n",
+				"db/messages.go":      "package db\n\nvar Messages = map[string]string{\"help\": \"Help\"}\n",
+			},
+			want: []string{"i18n/fr/messages.go:3 warning: Key 'help' of locale en is missing from locale fr (fr.Messages)"},
+		},
+		{
+			name: "translation files",
+			files: map[string]string{
+				"locales/en.json":          `{"greeting": {"hello": "Hello, {name}"}, "bye": "Bye"}`,
+				"locales/fr.json":          `{"greeting": {"hello": "Bonjour"}, "bye": "Au revoir"}`,
+				"locales/messages.en.yaml": "menu:\n  open: Open\n  close: Close\n",
+				"locales/messages.fr.toml": "[menu]\nopen = \"Ouvrir\"\nquit = \"Quitter\"\n",
+			},
+			want: []string{
+				"locales/fr.json:1 warning: Placeholders of 'greeting.hello' differ in locale fr: [{name}] in en, [] in fr",
+				"locales/messages.fr.toml:1 warning: Key 'menu.close' of locale en is missing from locale fr (locales/messages.fr.toml)",
+				"locales/messages.fr.toml:3 warning: Key 'menu.quit' of locale fr is not in source locale en (locales/messages.en.yaml)",
+			},
+		},
+		{
+			name: "configured source locale",
+			files: map[string]string{
+				"locales/en.json": `{"bye": "Bye"}`,
+				"locales/fr.json": `{"bye": "Au revoir", "help": "Aide"}`,
+			},
+			options: map[string]any{"source": "fr"},
+			want:    []string{"locales/en.json:1 warning: Key 'help' of locale fr is missing from locale en (locales/en.json)"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			project := &config.Project{Detectors: map[string]config.DetectorConfig{
+				"message-locales": {Options: test.options},
+			}}
+			root, results := runDetectors(t, test.files, project, "message-locales")
+			if got := describeFindings(root, results); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("findings = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package detectors
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// migration describes how the uses of a deprecated package are rewritten.
+type migration struct {
+	// Package replaces the whole package when every symbol keeps its name.
+	Package string
+	// Symbols maps each symbol to its replacement when they move to several
+	// packages. A symbol missing from the map has no drop-in replacement,
+	// and files using it are left alone.
+	Symbols map[string]symbolMove
+	// Requires is a module that go.mod must already require for the
+	// replacement to build.
+	Requires string
+}
+
+// symbolMove is the new home of a symbol.
+type symbolMove struct {
+	Path string
+	Name string
+}
+
+// migrations are the automatic rewrites of the built-in deprecated packages.
+var migrations = map[string]migration{
+	"io/ioutil": {Symbols: map[string]symbolMove{
+		"ReadAll":   {"io", "ReadAll"},
+		"ReadFile":  {"os", "ReadFile"},
+		"WriteFile": {"os", "WriteFile"},
+		"NopCloser": {"io", "NopCloser"},
+		"Discard":   {"io", "Discard"},
+		"TempDir":   {"os", "MkdirTemp"},
+		"TempFile":  {"os", "CreateTemp"},
+		// ReadDir is missing on purpose: os.ReadDir returns []fs.DirEntry
+		// rather than []fs.FileInfo, so callers need a manual change
+	}},
+	"golang.org/x/net/context":         {Package: "context"},
+	"golang.org/x/crypto/ssh/terminal": {Package: "golang.org/x/term", Requires: "golang.org/x/term"},
+}
+
+// migrationEdits rewrites every use of the package imported by importSpec
+// and replaces the import with the packages now needed. It returns nil when
+// the rewrite is not known to be safe: the file was not type-checked, a
+// symbol has no replacement, a new package name would be shadowed or
+// clash, or a required module is missing from go.mod.
+func migrationEdits(program *Program, file *File, importSpec *ast.ImportSpec) []TextEdit {
+	importPath, _ := strconv.Unquote(importSpec.Path.Value)
+	plan, ok := migrations[importPath]
+	info := program.TypesInfo()
+	if !ok || !file.Checked {
+		return nil
+	}
+	if importSpec.Name != nil && (importSpec.Name.Name == "_" || importSpec.Name.Name == ".") {
+		return nil
+	}
+	if plan.Requires != "" && !moduleRequires(file.Path, plan.Requires) {
+		return nil
+	}
+	oldName, ok := info.Implicits[importSpec].(*types.PkgName)
+	if importSpec.Name != nil {
+		oldName, ok = info.Defs[importSpec.Name].(*types.PkgName)
+	}
+	if !ok {
+		return nil
+	}
+
+	// Local names of the packages the file imports already
+	imported := make(map[string]string)
+	for _, spec := range file.AST.Imports {
+		if spec == importSpec || (spec.Name != nil && (spec.Name.Name == "_" || spec.Name.Name == ".")) {
+			continue
+		}
+		if pkgName := importedName(info, spec); pkgName != nil {
+			imported[pkgName.Imported().Path()] = pkgName.Name()
+		}
+	}
+
+	var edits []TextEdit
+	added := make(map[string]bool)
+	valid := true
+	ast.Inspect(file.AST, func(astNode ast.Node) bool {
+		selector, isSelector := astNode.(*ast.SelectorExpr)
+		if !valid || !isSelector {
+			return valid
+		}
+		qualifier, isIdent := selector.X.(*ast.Ident)
+		if !isIdent || info.Uses[qualifier] != oldName {
+			return true
+		}
+		move := symbolMove{Path: plan.Package, Name: selector.Sel.Name}
+		if plan.Package == "" {
+			if move, ok = plan.Symbols[selector.Sel.Name]; !ok {
+				valid = false
+				return false
+			}
+		}
+		localName, found := imported[move.Path]
+		if !found {
+			localName = path.Base(move.Path)
+			added[move.Path] = true
+		}
+		// The new name must denote the new package, or the import it replaces
+		scope := oldName.Pkg().Scope().Innermost(qualifier.Pos())
+		if scope == nil {
+			valid = false
+			return false
+		}
+		if _, existing := scope.LookupParent(localName, qualifier.Pos()); existing != nil && existing != oldName {
+			if pkgName, isPkg := existing.(*types.PkgName); !isPkg || pkgName.Imported().Path() != move.Path {
+				valid = false
+				return false
+			}
+		}
+		if localName != qualifier.Name {
+			edits = append(edits, identEdit(program.Fset, qualifier, localName))
+		}
+		if move.Name != selector.Sel.Name {
+			edits = append(edits, identEdit(program.Fset, selector.Sel, move.Name))
+		}
+		return true
+	})
+	if !valid {
+		return nil
+	}
+	importEdit, ok := replaceImportEdit(program, file, importSpec, added)
+	if !ok {
+		return nil
+	}
+	return append(edits, importEdit)
+}
+
+// importedName returns the package name an import spec declares.
+func importedName(info *types.Info, spec *ast.ImportSpec) *types.PkgName {
+	if spec.Name != nil {
+		pkgName, _ := info.Defs[spec.Name].(*types.PkgName)
+		return pkgName
+	}
+	pkgName, _ := info.Implicits[spec].(*types.PkgName)
+	return pkgName
+}
+
+// replaceImportEdit replaces importSpec with imports of the added paths, or
+// deletes it when nothing needs to be added. gofmt sorts the block later.
+func replaceImportEdit(program *Program, file *File, importSpec *ast.ImportSpec, added map[string]bool) (TextEdit, bool) {
+	var paths []string
+	for importPath := range added {
+		paths = append(paths, strconv.Quote(importPath))
+	}
+	sort.Strings(paths)
+
+	var genDecl *ast.GenDecl
+	for _, decl := range file.AST.Decls {
+		if candidate, ok := decl.(*ast.GenDecl); ok && candidate.Tok == token.IMPORT {
+			for _, spec := range candidate.Specs {
+				if spec == importSpec {
+					genDecl = candidate
+				}
+			}
+		}
+	}
+	if genDecl == nil {
+		return TextEdit{}, false
+	}
+
+	start := program.Position(importSpec.Pos())
+	end := program.Position(importSpec.End())
+	if !genDecl.Lparen.IsValid() {
+		// A lone "import x" is replaced in place, becomes a block, or disappears
+		start, end = program.Position(genDecl.Pos()), program.Position(genDecl.End())
+		switch len(paths) {
+		case 0:
+			return deleteLinesEdit(file.Path, file.Src, start.Offset, end.Offset)
+		case 1:
+			return TextEdit{File: file.Path, Start: start.Offset, End: end.Offset, NewText: "import " + paths[0]}, true
+		}
+		text := "import (\n\t" + strings.Join(paths, "\n\t") + "\n)"
+		return TextEdit{File: file.Path, Start: start.Offset, End: end.Offset, NewText: text}, true
+	}
+	if len(paths) == 0 {
+		if importSpec.Comment != nil {
+			end = program.Position(importSpec.Comment.End())
+		}
+		return deleteLinesEdit(file.Path, file.Src, start.Offset, end.Offset)
+	}
+	return TextEdit{File: file.Path, Start: start.Offset, End: end.Offset, NewText: strings.Join(paths, "\n\t")}, true
+}
+
+// moduleRequires reports whether the go.mod governing path requires module.
+func moduleRequires(path, module string) bool {
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		goModPath := filepath.Join(dir, "go.mod")
+		if data, err := os.ReadFile(goModPath); err == nil {
+			_, found := parseGoMod(goModPath, data).Require[module]
+			return found
+		}
+		if filepath.Dir(dir) == dir {
+			return false
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package detectors
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMigrationEdits(t *testing.T) {
+	tests := []struct {
+		name         string
+		source       string
+		wantFindings []string
+		wantEdits    []string
+	}{
+		{
+			name: "symbols moving to several packages",
+			source: `package app
+
+import (
+	"fmt"
+	"io/ioutil"
+)
+
+func Load(name string) {
+	data, _ := ioutil.ReadFile(name)
+	fmt.Println(ioutil.NopCloser(nil), data)
+}
+`,
+			wantFindings: []string{
+				"app.go:5 warning: Package 'io/ioutil' is deprecated since Go 1.16. Deprecated: use io and os packages instead",
+			},
+			wantEdits: []string{
+				`app.go:9: "ioutil" -> "os"`,
+				`app.go:10: "ioutil" -> "io"`,
+				`app.go:5: "\"io/ioutil\"" -> "\"io\"\n\t\"os\""`,
+			},
+		},
+		{
+			name: "package already imported under its own name",
+			source: `package app
+
+import (
+	"io/ioutil"
+	"os"
+)
+
+func Load(name string) ([]byte, error) {
+	os.Getenv("HOME")
+	return ioutil.ReadFile(name)
+}
+`,
+			wantEdits: []string{
+				`app.go:10: "ioutil" -> "os"`,
+				`app.go:4: "\t\"io/ioutil\"\n" -> ""`,
+			},
+		},
+		{
+			name: "lone import with a renamed symbol",
+			source: `package app
+
+import "io/ioutil"
+
+func Temp() (string, error) {
+	return ioutil.TempDir("", "app")
+}
+`,
+			wantEdits: []string{
+				`app.go:6: "ioutil" -> "os"`,
+				`app.go:6: "TempDir" -> "MkdirTemp"`,
+				`app.go:3: "import \"io/ioutil\"" -> "import \"os\""`,
+			},
+		},
+		{
+			name: "named import",
+			source: `package app
+
+import legacy "io/ioutil"
+
+func Load(name string) ([]byte, error) {
+	return legacy.ReadFile(name)
+}
+`,
+			wantEdits: []string{
+				`app.go:6: "legacy" -> "os"`,
+				`app.go:3: "import legacy \"io/ioutil\"" -> "import \"os\""`,
+			},
+		},
+		{
+			name: "symbol without a replacement",
+			source: `package app
+
+import "io/ioutil"
+
+func List(dir string) int {
+	entries, _ := ioutil.ReadDir(dir)
+	data, _ := ioutil.ReadFile(dir)
+	return len(entries) + len(data)
+}
+`,
+			wantEdits: []string{},
+		},
+		{
+			name: "new package name is shadowed",
+			source: `package app
+
+import "io/ioutil"
+
+func Load(os string) ([]byte, error) {
+	return ioutil.ReadFile(os)
+}
+`,
+			wantEdits: []string{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root, results := runDetectors(t, map[string]string{"app.go": test.source}, nil, "deprecated-packages")
+			if test.wantFindings != nil {
+				if got := describeFindings(root, results); !reflect.DeepEqual(got, test.wantFindings) {
+					t.Errorf("findings = %q, want %q", got, test.wantFindings)
+				}
+			}
+			if got := describeEdits(t, root, results); !reflect.DeepEqual(got, test.wantEdits) {
+				t.Errorf("edits = %q, want %q", got, test.wantEdits)
+			}
+		})
+	}
+}
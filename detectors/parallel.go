@@ -0,0 +1,64 @@
+package detectors
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+)
+
+// workerPool bounds the number of file-level tasks running at once across
+// every detector of a run.
+type workerPool chan struct{}
+
+// newWorkerPool creates a pool of the given size; sizes below one use
+// GOMAXPROCS.
+func newWorkerPool(size int) workerPool {
+	if size < 1 {
+		size = runtime.GOMAXPROCS(0)
+	}
+	return make(workerPool, size)
+}
+
+// size returns the number of tasks the pool runs at once.
+func (pool workerPool) size() int {
+	return cap(pool)
+}
+
+// forEach calls task for every index in [0, count) using the pool. It
+// returns once every task has finished.
+func (pool workerPool) forEach(count int, task func(idx int)) {
+	var wait sync.WaitGroup
+	for idx := 0; idx < count; idx++ {
+		pool <- struct{}{}
+		wait.Add(1)
+		go func(idx int) {
+			defer func() {
+				<-pool
+				wait.Done()
+			}()
+			task(idx)
+		}(idx)
+	}
+	wait.Wait()
+}
+
+// ForEachFile runs check on every file concurrently, bounded by the run's
+// worker pool. Findings and errors are returned in file order, so the result
+// does not depend on scheduling.
+func (pass *Pass) ForEachFile(files []*File, check func(file *File) ([]Finding, error)) ([]Finding, error) {
+	fileFindings := make([][]Finding, len(files))
+	fileErrs := make([]error, len(files))
+	pool := pass.pool
+	if pool == nil {
+		pool = newWorkerPool(1)
+	}
+	pool.forEach(len(files), func(idx int) {
+		fileFindings[idx], fileErrs[idx] = check(files[idx])
+	})
+
+	var findings []Finding
+	for _, found := range fileFindings {
+		findings = append(findings, found...)
+	}
+	return findings, errors.Join(fileErrs...)
+}
@@ -0,0 +1,147 @@
+package detectors
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// File is a Go source file of the analyzed project.
+type File struct {
+	// Path is the absolute path of the file.
+	Path string
+	Src  []byte
+	// AST is nil when the file could not be read. It may be partial when
+	// ParseErr is set.
+	AST *ast.File
+	// ParseErr is the error returned while reading or parsing the file.
+	ParseErr error
+	IsTest   bool
+	// Package is the package the file belongs to; nil when AST is nil.
+	Package *Package
+	// Checked reports whether the file took part in type checking, that is
+	// whether TypesInfo covers its identifiers.
+	Checked bool
+}
+
+// Package groups the files of one directory that share a package name.
+type Package struct {
+	Dir   string
+	Name  string
+	Files []*File
+	// Path is the import path derived from the enclosing go.mod; external
+	// test packages get the "_test" suffix.
+	Path string
+	// Types is set by TypesInfo. It is nil when the package has no file
+	// matching the build context.
+	Types *types.Package
+	// TypeErrors are the errors found while type checking. They are
+	// tolerated: the information that could be computed is still recorded.
+	TypeErrors []error
+	state      checkState
+}
+
+// Program is the parsed project shared by every detector of a run, so that
+// each file is read and parsed exactly once.
+type Program struct {
+	Root string
+	Fset *token.FileSet
+	// Files are sorted by path.
+	Files []*File
+	// Packages are sorted by directory and name.
+	Packages []*Package
+	byPath   map[string]*File
+
+	typesOnce sync.Once
+	info      *types.Info
+
+	referencesOnce sync.Once
+	references     map[types.Object][]*ast.Ident
+}
+
+// LoadProgram walks root and parses every Go file beneath it, with comments,
+// using up to jobs parallel workers (GOMAXPROCS when jobs is below one).
+// Like the go tool, it skips testdata and vendor directories and those whose
+// name starts with "." or "_".
+func LoadProgram(root string, jobs int) (*Program, error) {
+	program := &Program{
+		Root:   root,
+		Fset:   token.NewFileSet(),
+		byPath: make(map[string]*File),
+	}
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != root && ignoredDir(info.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+		file := &File{Path: path, IsTest: strings.HasSuffix(path, "_test.go")}
+		program.Files = append(program.Files, file)
+		program.byPath[path] = file
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// token.FileSet is safe for concurrent use, so files are parsed in parallel
+	newWorkerPool(jobs).forEach(len(program.Files), func(idx int) {
+		file := program.Files[idx]
+		file.Src, file.ParseErr = os.ReadFile(file.Path)
+		if file.ParseErr == nil {
+			file.AST, file.ParseErr = parser.ParseFile(program.Fset, file.Path, file.Src, parser.ParseComments|parser.AllErrors)
+		}
+	})
+
+	modules := make(moduleCache)
+	packages := make(map[[2]string]*Package)
+	for _, file := range program.Files {
+		if file.AST == nil {
+			continue
+		}
+		key := [2]string{filepath.Dir(file.Path), file.AST.Name.Name}
+		pkg, ok := packages[key]
+		if !ok {
+			pkg = &Package{Dir: key[0], Name: key[1], Path: modules.importPath(key[0], key[1])}
+			packages[key] = pkg
+			program.Packages = append(program.Packages, pkg)
+		}
+		pkg.Files = append(pkg.Files, file)
+		file.Package = pkg
+	}
+	sort.Slice(program.Packages, func(i, j int) bool {
+		if program.Packages[i].Dir != program.Packages[j].Dir {
+			return program.Packages[i].Dir < program.Packages[j].Dir
+		}
+		return program.Packages[i].Name < program.Packages[j].Name
+	})
+	return program, nil
+}
+
+// ignoredDir reports whether the go tool ignores a directory of this name.
+func ignoredDir(name string) bool {
+	return name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")
+}
+
+// File returns the loaded file with the given absolute path, or nil.
+func (program *Program) File(path string) *File {
+	return program.byPath[path]
+}
+
+// Position converts a position in one of the program's files.
+func (program *Program) Position(pos token.Pos) token.Position {
+	return program.Fset.Position(pos)
+}
@@ -0,0 +1,121 @@
+package detectors
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Aadi-IRON/agni/config"
+)
+
+// registry holds every detector registered through Register, keyed by name.
+var registry = map[string]Detector{}
+
+// registrationOrder remembers the order in which detectors were registered.
+var registrationOrder []string
+
+// Register adds a detector to the registry. It is meant to be called from an
+// init function and panics if the name is empty or already taken.
+func Register(detector Detector) {
+	name := detector.Name()
+	if name == "" {
+		panic("detectors: Register called with an unnamed detector")
+	}
+	if _, exists := registry[name]; exists {
+		panic("detectors: Register called twice for detector " + name)
+	}
+	registry[name] = detector
+	registrationOrder = append(registrationOrder, name)
+}
+
+// Lookup returns the registered detector with the given name.
+func Lookup(name string) (Detector, bool) {
+	detector, ok := registry[name]
+	return detector, ok
+}
+
+// All returns every registered detector. Built-in detectors come first in
+// defaultOrder, followed by any others in registration order.
+func All() []Detector {
+	rank := make(map[string]int, len(defaultOrder))
+	for idx, name := range defaultOrder {
+		rank[name] = idx
+	}
+	names := append([]string(nil), registrationOrder...)
+	sort.SliceStable(names, func(i, j int) bool {
+		rankI, knownI := rank[names[i]]
+		rankJ, knownJ := rank[names[j]]
+		if knownI && knownJ {
+			return rankI < rankJ
+		}
+		return knownI && !knownJ
+	})
+	all := make([]Detector, 0, len(names))
+	for _, name := range names {
+		all = append(all, registry[name])
+	}
+	return all
+}
+
+// Select returns the detectors to run. When only is non-empty the detectors
+// are returned in that order; otherwise all detectors are returned. Any name
+// listed in skip is left out.
+func Select(only, skip []string) ([]Detector, error) {
+	skipped := make(map[string]bool, len(skip))
+	for _, name := range skip {
+		if _, ok := registry[name]; !ok {
+			return nil, fmt.Errorf("unknown detector %q", name)
+		}
+		skipped[name] = true
+	}
+
+	candidates := All()
+	if len(only) > 0 {
+		candidates = candidates[:0:0]
+		for _, name := range only {
+			detector, ok := registry[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown detector %q", name)
+			}
+			candidates = append(candidates, detector)
+		}
+	}
+
+	var selected []Detector
+	seen := make(map[string]bool)
+	for _, detector := range candidates {
+		if skipped[detector.Name()] || seen[detector.Name()] {
+			continue
+		}
+		seen[detector.Name()] = true
+		selected = append(selected, detector)
+	}
+	return selected, nil
+}
+
+// CheckProject reports detectors named in the project configuration that are
+// not registered, which usually means a typo in the configuration file.
+func CheckProject(project *config.Project) error {
+	var unknown []string
+	for name := range project.Detectors {
+		if _, ok := registry[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	return fmt.Errorf("%s: unknown detector(s) %s", project.Path, strings.Join(unknown, ", "))
+}
+
+// ParseNames splits a comma-separated list of detector names.
+func ParseNames(list string) []string {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,361 @@
+package detectors
+
+import (
+	"bufio"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// stdlibDeprecation is a deprecated symbol of the standard library.
+type stdlibDeprecation struct {
+	// Notice is the "Deprecated:" paragraph of the symbol's doc comment.
+	Notice string
+	// Since is the Go release that deprecated the symbol, such as "Go 1.18",
+	// or empty when neither the notices nor the api files say.
+	Since string
+}
+
+// packageNoticeKey keys the notice of a deprecated package in stdlib.notices.
+const packageNoticeKey = ""
+
+// noticeRelease matches the release a notice names: "As of Go 1.16, ...".
+var noticeRelease = regexp.MustCompile(`\bGo 1(?:\.\d+)?\b`)
+
+// stdlib caches what has been read from the local GOROOT. Detectors check
+// files in parallel, so access is guarded by the mutex.
+var stdlib = struct {
+	sync.Mutex
+	// notices maps import paths to the deprecated symbols of the package,
+	// keyed by "Name" or "Type.Member".
+	notices map[string]map[string]string
+	// since maps "path:Name" keys to the release that deprecated them.
+	since     map[string]string
+	sinceOnce sync.Once
+}{notices: make(map[string]map[string]string)}
+
+// lookupStdlibDeprecation returns the deprecation of a standard library
+// symbol, keyed as in stdlib.notices. The release is the one the symbol's
+// notice names, else the one its package's notice names, else the one of the
+// api files, which may be later than the actual deprecation.
+func lookupStdlibDeprecation(importPath, key string) (stdlibDeprecation, bool) {
+	stdlib.Lock()
+	notices, loaded := stdlib.notices[importPath]
+	if !loaded {
+		notices = readDeprecationNotices(filepath.Join(build.Default.GOROOT, "src", filepath.FromSlash(importPath)))
+		stdlib.notices[importPath] = notices
+	}
+	stdlib.Unlock()
+	notice, found := notices[key]
+	if !found {
+		return stdlibDeprecation{}, false
+	}
+	if since := noticeRelease.FindString(notice); since != "" {
+		return stdlibDeprecation{Notice: notice, Since: since}, true
+	}
+	if since := noticeRelease.FindString(notices[packageNoticeKey]); since != "" {
+		return stdlibDeprecation{Notice: notice, Since: since}, true
+	}
+	stdlib.sinceOnce.Do(func() {
+		stdlib.since = readDeprecationReleases(filepath.Join(build.Default.GOROOT, "api"))
+	})
+	return stdlibDeprecation{Notice: notice, Since: stdlib.since[importPath+":"+key]}, true
+}
+
+// isStdlibPath reports whether an import path belongs to the standard library.
+func isStdlibPath(importPath string) bool {
+	first, _, _ := strings.Cut(importPath, "/")
+	return !strings.Contains(first, ".") && first != "C" && first != "unsafe"
+}
+
+// readDeprecationNotices parses the package in dir and returns the notices
+// of its deprecated symbols, and of the package itself under packageNoticeKey.
+// A doc comment on a grouped declaration applies to every spec of the group.
+func readDeprecationNotices(dir string) map[string]string {
+	notices := make(map[string]string)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return notices
+	}
+	fset := token.NewFileSet()
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		if match, err := build.Default.MatchFile(dir, name); err != nil || !match {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		addNotice(notices, packageNoticeKey, file.Doc)
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				key := decl.Name.Name
+				if decl.Recv != nil && len(decl.Recv.List) > 0 {
+					key = receiverName(decl.Recv.List[0].Type) + "." + key
+				}
+				addNotice(notices, key, decl.Doc)
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						addNotice(notices, spec.Name.Name, spec.Doc, decl.Doc)
+						addMemberNotices(notices, spec)
+					case *ast.ValueSpec:
+						for _, name := range spec.Names {
+							addNotice(notices, name.Name, spec.Doc, spec.Comment, decl.Doc)
+						}
+					}
+				}
+			}
+		}
+	}
+	return notices
+}
+
+// addMemberNotices records deprecated struct fields and interface methods.
+func addMemberNotices(notices map[string]string, spec *ast.TypeSpec) {
+	var members *ast.FieldList
+	switch typ := spec.Type.(type) {
+	case *ast.StructType:
+		members = typ.Fields
+	case *ast.InterfaceType:
+		members = typ.Methods
+	}
+	if members == nil {
+		return
+	}
+	for _, member := range members.List {
+		for _, name := range member.Names {
+			addNotice(notices, spec.Name.Name+"."+name.Name, member.Doc, member.Comment)
+		}
+	}
+}
+
+// addNotice records the first "Deprecated:" paragraph found in docs.
+func addNotice(notices map[string]string, key string, docs ...*ast.CommentGroup) {
+	for _, doc := range docs {
+		if notice := deprecationNotice(doc); notice != "" {
+			notices[key] = notice
+			return
+		}
+	}
+}
+
+// deprecationNotice returns the "Deprecated:" paragraph of a doc comment,
+// on a single line, or "".
+func deprecationNotice(doc *ast.CommentGroup) string {
+	if doc == nil {
+		return ""
+	}
+	for _, paragraph := range strings.Split(doc.Text(), "\n\n") {
+		if strings.HasPrefix(paragraph, "Deprecated:") {
+			return strings.Join(strings.Fields(paragraph), " ")
+		}
+	}
+	return ""
+}
+
+// receiverName returns the type name of a method receiver expression.
+func receiverName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(expr.X)
+	case *ast.IndexExpr:
+		return receiverName(expr.X)
+	case *ast.IndexListExpr:
+		return receiverName(expr.X)
+	case *ast.Ident:
+		return expr.Name
+	}
+	return ""
+}
+
+// readDeprecationReleases reads the api/go1.*.txt files of GOROOT, which
+// mark each symbol with "//deprecated" in the release that deprecated it.
+// Symbols deprecated before the marker existed are listed in a later file,
+// so "since" may be later than the actual deprecation, never earlier.
+func readDeprecationReleases(dir string) map[string]string {
+	since := make(map[string]string)
+	minor := make(map[string]int)
+	paths, _ := filepath.Glob(filepath.Join(dir, "go1*.txt"))
+	for _, path := range paths {
+		release := strings.TrimSuffix(filepath.Base(path), ".txt")
+		version := 0
+		if rest, ok := strings.CutPrefix(release, "go1."); ok {
+			if version, _ = strconv.Atoi(rest); version == 0 {
+				continue
+			}
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			// The marker may be followed by an issue number: "//deprecated #56319"
+			line, _, marked := strings.Cut(scanner.Text(), " //deprecated")
+			if !marked {
+				continue
+			}
+			key, ok := parseAPILine(line)
+			if !ok {
+				continue
+			}
+			if previous, seen := minor[key]; !seen || version < previous {
+				minor[key] = version
+				since[key] = "Go 1." + strconv.Itoa(version)
+				if version == 0 {
+					since[key] = "Go 1"
+				}
+			}
+		}
+		file.Close()
+	}
+	return since
+}
+
+// parseAPILine turns an api file line such as "pkg strings, func Title" or
+// "pkg archive/zip, method (*File) ModTime" into a "path:Name" key.
+func parseAPILine(line string) (string, bool) {
+	rest, ok := strings.CutPrefix(line, "pkg ")
+	if !ok {
+		return "", false
+	}
+	importPath, rest, ok := strings.Cut(rest, ", ")
+	if !ok {
+		return "", false
+	}
+	// Drop a platform qualifier such as "syscall (linux-386)"
+	importPath, _, _ = strings.Cut(importPath, " ")
+	kind, rest, _ := strings.Cut(rest, " ")
+	var key string
+	switch kind {
+	case "func":
+		key, _, _ = strings.Cut(rest, "(")
+	case "const", "var":
+		key, _, _ = strings.Cut(rest, " ")
+	case "type":
+		name, member, hasMember := strings.Cut(rest, ", ")
+		name, _, _ = strings.Cut(name, " ")
+		key = name
+		if hasMember {
+			member, _, _ = strings.Cut(member, " ")
+			key += "." + member
+		}
+	case "method":
+		receiver, name, _ := strings.Cut(rest, ") ")
+		receiver = strings.Trim(receiver, "(*")
+		receiver, _, _ = strings.Cut(receiver, "[")
+		name, _, _ = strings.Cut(name, "(")
+		key = receiver + "." + name
+	default:
+		return "", false
+	}
+	return importPath + ":" + key, key != ""
+}
+
+// fieldOwners maps the fields of the struct types declared at package level
+// to the name of their type, per package.
+var fieldOwners = struct {
+	sync.Mutex
+	byPackage map[*types.Package]map[*types.Var]string
+}{byPackage: make(map[*types.Package]map[*types.Var]string)}
+
+// fieldOwner returns the name of the package-level struct type declaring
+// field, or "" for fields of anonymous structs.
+func fieldOwner(field *types.Var) string {
+	field = field.Origin()
+	if field.Pkg() == nil {
+		return ""
+	}
+	fieldOwners.Lock()
+	defer fieldOwners.Unlock()
+	owners, ok := fieldOwners.byPackage[field.Pkg()]
+	if !ok {
+		owners = make(map[*types.Var]string)
+		scope := field.Pkg().Scope()
+		for _, name := range scope.Names() {
+			typeName, isType := scope.Lookup(name).(*types.TypeName)
+			if !isType {
+				continue
+			}
+			if structType, isStruct := typeName.Type().Underlying().(*types.Struct); isStruct {
+				for idx := 0; idx < structType.NumFields(); idx++ {
+					owners[structType.Field(idx)] = name
+				}
+			}
+		}
+		fieldOwners.byPackage[field.Pkg()] = owners
+	}
+	return owners[field]
+}
+
+// stdlibSymbolFindings reports every use of a deprecated standard library
+// function, type, constant, variable, method or field in a type-checked file.
+// Symbols of the packages whose import was already reported are left out.
+func stdlibSymbolFindings(program *Program, file *File, reported map[string]bool) []Finding {
+	info := program.TypesInfo()
+	if !file.Checked {
+		return nil
+	}
+	var findings []Finding
+	report := func(ident *ast.Ident, object types.Object, key string) {
+		if object.Pkg() == nil || !isStdlibPath(object.Pkg().Path()) || reported[object.Pkg().Path()] {
+			return
+		}
+		deprecation, found := lookupStdlibDeprecation(object.Pkg().Path(), key)
+		if !found {
+			return
+		}
+		message := fmt.Sprintf("'%s.%s' is deprecated", object.Pkg().Name(), key)
+		if deprecation.Since != "" {
+			message += " since " + deprecation.Since
+		}
+		finding := NewFinding(program.Position(ident.Pos()), program.Position(ident.End()), message)
+		finding.SuggestedFix = strings.TrimSpace(strings.TrimPrefix(deprecation.Notice, "Deprecated:"))
+		findings = append(findings, finding)
+	}
+
+	ast.Inspect(file.AST, func(astNode ast.Node) bool {
+		ident, ok := astNode.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		switch object := info.Uses[ident].(type) {
+		case *types.Func:
+			if receiver := receiverTypeName(object); receiver != nil {
+				report(ident, object, receiver.Name()+"."+object.Name())
+			} else if object.Parent() == object.Pkg().Scope() {
+				report(ident, object, object.Name())
+			}
+		case *types.Var:
+			if object.IsField() {
+				if owner := fieldOwner(object); owner != "" {
+					report(ident, object, owner+"."+object.Name())
+				}
+			} else if object.Pkg() != nil && object.Parent() == object.Pkg().Scope() {
+				report(ident, object, object.Name())
+			}
+		case *types.Const, *types.TypeName:
+			if object.Pkg() != nil && object.Parent() == object.Pkg().Scope() {
+				report(ident, object, object.Name())
+			}
+		}
+		return true
+	})
+	return findings
+}
@@ -0,0 +1,268 @@
+package detectors
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+	"sync"
+)
+
+// SuppressionsRule is the name of the detector that reports malformed and
+// unused suppression directives.
+const SuppressionsRule = "suppressions"
+
+const (
+	ignoreDirective     = "//agni:ignore"
+	fileIgnoreDirective = "//agni:file-ignore"
+	// allRules matches every rule in a suppression directive.
+	allRules = "all"
+)
+
+func init() {
+	Register(Document(
+		NewDetector(
+			SuppressionsRule,
+			"Reports //agni:ignore directives that are malformed or no longer suppress anything",
+			CategoryStyle,
+			SeverityWarning,
+			// The runner computes these findings after every other detector has run
+			func(*Pass) ([]Finding, error) { return nil, nil },
+		),
+		Explanation{
+			Rationale: `Findings can be silenced with a directive naming the rule and a reason:
+
+    //agni:ignore <rule>[,<rule>...] -- reason
+    //agni:file-ignore <rule>[,<rule>...] -- reason
+
+An ignore directive applies to its own line and, when it is on a line of its
+own, to the next line. Placed in the doc comment of a declaration it applies
+to the whole declaration. A file-ignore directive applies to the whole file.
+Use "all" to match every rule.
+
+Directives without a known rule or a reason are reported, as are directives
+that no longer suppress any finding, so that suppressions do not rot.`,
+			Bad: `//agni:ignore unused-params
+func Handle(ctx context.Context, req Request) {}`,
+			Good: `//agni:ignore unused-params -- signature is fixed by the router
+func Handle(ctx context.Context, req Request) {}`,
+		},
+	))
+}
+
+// suppression is a single //agni:ignore or //agni:file-ignore directive.
+type suppression struct {
+	position token.Position
+	rules    []string
+	// wholeFile is set for //agni:file-ignore directives.
+	wholeFile bool
+	// firstLine and lastLine delimit the lines the directive applies to.
+	firstLine int
+	lastLine  int
+	// used records which rules matched at least one finding.
+	used map[string]bool
+}
+
+// matches reports whether the directive suppresses the given finding.
+func (directive *suppression) matches(finding Finding) bool {
+	if !directive.wholeFile && (finding.Line < directive.firstLine || finding.Line > directive.lastLine) {
+		return false
+	}
+	for _, rule := range directive.rules {
+		if rule == allRules || rule == finding.RuleID {
+			directive.used[rule] = true
+			return true
+		}
+	}
+	return false
+}
+
+// suppressionIndex holds the suppression directives of a run by file. The
+// directives record the rules they matched, so detectors that consult them
+// while running share them with the runner through the mutex.
+type suppressionIndex struct {
+	mutex      sync.Mutex
+	directives []*suppression
+	byFile     map[string][]*suppression
+	// problems are findings for malformed directives.
+	problems []Finding
+}
+
+// newSuppressionIndex collects the suppression directives of the program.
+func newSuppressionIndex(program *Program) *suppressionIndex {
+	directives, problems := collectSuppressions(program)
+	index := &suppressionIndex{directives: directives, byFile: make(map[string][]*suppression), problems: problems}
+	for _, directive := range directives {
+		index.byFile[directive.position.Filename] = append(index.byFile[directive.position.Filename], directive)
+	}
+	return index
+}
+
+// suppressed reports whether a directive suppresses the finding, and marks
+// every matching directive as used.
+func (index *suppressionIndex) suppressed(finding Finding) bool {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+	suppressed := false
+	for _, directive := range index.byFile[finding.File] {
+		if directive.matches(finding) {
+			suppressed = true
+		}
+	}
+	return suppressed
+}
+
+// applySuppressions removes suppressed findings from results. It returns
+// findings for malformed directives and for directives that suppressed nothing
+// although their rules ran.
+func applySuppressions(index *suppressionIndex, results []Result) []Finding {
+	problems := index.problems
+	ran := make(map[string]bool)
+	for idx := range results {
+		ran[results[idx].Detector.Name()] = true
+		kept := results[idx].Findings[:0]
+		for _, finding := range results[idx].Findings {
+			if !index.suppressed(finding) {
+				kept = append(kept, finding)
+			}
+		}
+		results[idx].Findings = kept
+	}
+
+	for _, directive := range index.directives {
+		for _, rule := range directive.rules {
+			if directive.used[rule] || (rule != allRules && !ran[rule]) {
+				continue
+			}
+			problems = append(problems, NewFinding(directive.position, token.Position{},
+				fmt.Sprintf("Suppression of '%s' does not match any finding", rule)))
+		}
+	}
+	return problems
+}
+
+// collectSuppressions returns the suppression directives of every parsed
+// file, along with findings for malformed ones.
+func collectSuppressions(program *Program) ([]*suppression, []Finding) {
+	var directives []*suppression
+	var problems []Finding
+	for _, file := range program.Files {
+		if file.ParseErr != nil {
+			// Files that do not parse are reported by the detectors themselves
+			continue
+		}
+		fileDirectives, fileProblems := fileSuppressions(program.Fset, file.AST, file.Src)
+		directives = append(directives, fileDirectives...)
+		problems = append(problems, fileProblems...)
+	}
+	return directives, problems
+}
+
+// fileSuppressions extracts the suppression directives of a parsed file.
+func fileSuppressions(fset *token.FileSet, node *ast.File, src []byte) ([]*suppression, []Finding) {
+	// Doc comments of declarations extend a directive to the whole declaration
+	declRanges := make(map[*ast.CommentGroup][2]int)
+	for _, decl := range node.Decls {
+		switch typed := decl.(type) {
+		case *ast.FuncDecl:
+			if typed.Doc != nil {
+				declRanges[typed.Doc] = lineRange(fset, typed)
+			}
+		case *ast.GenDecl:
+			if typed.Doc != nil {
+				declRanges[typed.Doc] = lineRange(fset, typed)
+			}
+			for _, spec := range typed.Specs {
+				switch typedSpec := spec.(type) {
+				case *ast.TypeSpec:
+					if typedSpec.Doc != nil {
+						declRanges[typedSpec.Doc] = lineRange(fset, typedSpec)
+					}
+				case *ast.ValueSpec:
+					if typedSpec.Doc != nil {
+						declRanges[typedSpec.Doc] = lineRange(fset, typedSpec)
+					}
+				}
+			}
+		}
+	}
+
+	var directives []*suppression
+	var problems []Finding
+	for _, group := range node.Comments {
+		for _, comment := range group.List {
+			var wholeFile bool
+			var rest string
+			switch {
+			case strings.HasPrefix(comment.Text, fileIgnoreDirective):
+				wholeFile, rest = true, strings.TrimPrefix(comment.Text, fileIgnoreDirective)
+			case strings.HasPrefix(comment.Text, ignoreDirective):
+				rest = strings.TrimPrefix(comment.Text, ignoreDirective)
+			default:
+				continue
+			}
+			position := fset.Position(comment.Pos())
+			rules, problem := parseSuppression(rest)
+			if problem != "" {
+				finding := NewFinding(position, fset.Position(comment.End()), "Malformed suppression: "+problem)
+				finding.SuggestedFix = "Use //agni:ignore <rule> -- reason"
+				problems = append(problems, finding)
+				continue
+			}
+
+			directive := &suppression{
+				position:  position,
+				rules:     rules,
+				wholeFile: wholeFile,
+				firstLine: position.Line,
+				lastLine:  position.Line,
+				used:      make(map[string]bool),
+			}
+			if lines, ok := declRanges[group]; ok {
+				directive.firstLine, directive.lastLine = lines[0], lines[1]
+			} else if startsLine(src, position) {
+				// A directive on a line of its own also covers the next line
+				directive.lastLine = position.Line + 1
+			}
+			directives = append(directives, directive)
+		}
+	}
+	return directives, problems
+}
+
+// parseSuppression parses the text after the directive name, which must look
+// like " rule[,rule...] -- reason". It returns a description of the problem
+// when the directive is malformed.
+func parseSuppression(text string) ([]string, string) {
+	if text != "" && text[0] != ' ' && text[0] != '\t' {
+		return nil, "unknown directive"
+	}
+	ruleList, reason, found := strings.Cut(text, "--")
+	if !found || strings.TrimSpace(reason) == "" {
+		return nil, "missing '-- reason'"
+	}
+	rules := ParseNames(ruleList)
+	if len(rules) == 0 {
+		return nil, "missing rule"
+	}
+	for _, rule := range rules {
+		if _, ok := registry[rule]; !ok && rule != allRules {
+			return nil, fmt.Sprintf("unknown rule '%s'", rule)
+		}
+	}
+	return rules, ""
+}
+
+// lineRange returns the first and last line of a node.
+func lineRange(fset *token.FileSet, node ast.Node) [2]int {
+	return [2]int{fset.Position(node.Pos()).Line, fset.Position(node.End()).Line}
+}
+
+// startsLine reports whether the text before a column of a line is blank.
+func startsLine(src []byte, position token.Position) bool {
+	lineStart := position.Offset - (position.Column - 1)
+	if lineStart < 0 || position.Offset > len(src) {
+		return false
+	}
+	return strings.TrimSpace(string(src[lineStart:position.Offset])) == ""
+}
@@ -0,0 +1,108 @@
+package detectors
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSuppression(t *testing.T) {
+	tests := []struct {
+		text        string
+		wantRules   []string
+		wantProblem string
+	}{
+		{text: " capital-vars -- generated code", wantRules: []string{"capital-vars"}},
+		{text: "\tcapital-vars, unused-params -- fixed signature", wantRules: []string{"capital-vars", "unused-params"}},
+		{text: " all -- vendored file", wantRules: []string{"all"}},
+		{text: "-next capital-vars -- reason", wantProblem: "unknown directive"},
+		{text: " capital-vars", wantProblem: "missing '-- reason'"},
+		{text: " capital-vars --   ", wantProblem: "missing '-- reason'"},
+		{text: " -- no rule", wantProblem: "missing rule"},
+		{text: " capital-var -- typo", wantProblem: "unknown rule 'capital-var'"},
+	}
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			rules, problem := parseSuppression(test.text)
+			if problem != test.wantProblem {
+				t.Fatalf("parseSuppression(%q) problem = %q, want %q", test.text, problem, test.wantProblem)
+			}
+			if !reflect.DeepEqual(rules, test.wantRules) {
+				t.Errorf("parseSuppression(%q) = %q, want %q", test.text, rules, test.wantRules)
+			}
+		})
+	}
+}
+
+func TestSuppressions(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []string
+	}{
+		{
+			name: "same line and next line",
+			source: `package app
+
+func Sum(Left, Right int) int { //agni:ignore capital-vars -- kept for the template
+	//agni:ignore capital-vars -- kept for the template
+	Total := Left + Right
+	Other := Total
+	return Other
+}
+`,
+			want: []string{"app.go:6 warning: Capitalized short variable 'Other'"},
+		},
+		{
+			name: "doc comment covers the declaration",
+			source: `package app
+
+// Sum adds.
+//
+//agni:ignore capital-vars -- kept for the template
+func Sum(Left, Right int) int {
+	Total := Left + Right
+	return Total
+}
+
+func Double(Value int) int { return 2 * Value }
+`,
+			want: []string{"app.go:11 warning: Capitalized function parameter 'Value'"},
+		},
+		{
+			name: "file-ignore and all",
+			source: `//agni:file-ignore all -- generated file
+
+package app
+
+func Sum(Left, Right int) int { return Left + Right }
+`,
+			want: []string{},
+		},
+		{
+			name: "malformed and unused directives",
+			source: `package app
+
+//agni:ignore capital-vars
+func Sum(left, right int) int {
+	//agni:ignore capital-vars -- nothing to suppress
+	total := left + right
+	//agni:ignore unused-params -- rule did not run
+	return total //agni:ignore nope -- unknown rule
+}
+`,
+			want: []string{
+				"app.go:3 warning: Malformed suppression: missing '-- reason'",
+				"app.go:5 warning: Suppression of 'capital-vars' does not match any finding",
+				"app.go:8 warning: Malformed suppression: unknown rule 'nope'",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root, results := runDetectors(t, map[string]string{"app.go": test.source}, nil, "capital-vars", SuppressionsRule)
+			if got := describeFindings(root, results); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("findings = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package detectors
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// checkState tracks a package through type checking so that import cycles
+// are reported instead of recursing forever.
+type checkState int
+
+const (
+	unchecked checkState = iota
+	checking
+	checked
+)
+
+// TypesInfo type-checks every package of the program the first time it is
+// called and returns the type information of all checked files. Packages of
+// the analyzed module are checked from the program's own ASTs; every other
+// import (the standard library, the module cache) is type-checked from
+// source. Type errors are tolerated and recorded on the package, so the
+// result may be partial: detectors should fall back to syntax for files
+// that are not Checked and for identifiers missing from the maps.
+//
+// TypesInfo is safe for concurrent use.
+func (program *Program) TypesInfo() *types.Info {
+	program.typesOnce.Do(func() {
+		program.info = &types.Info{
+			Types:      make(map[ast.Expr]types.TypeAndValue),
+			Defs:       make(map[*ast.Ident]types.Object),
+			Uses:       make(map[*ast.Ident]types.Object),
+			Implicits:  make(map[ast.Node]types.Object),
+			Selections: make(map[*ast.SelectorExpr]*types.Selection),
+			Scopes:     make(map[ast.Node]*types.Scope),
+		}
+		imports := &programImporter{
+			program:  program,
+			fallback: importer.ForCompiler(program.Fset, "source", nil).(types.ImporterFrom),
+		}
+		for _, pkg := range program.Packages {
+			imports.check(pkg)
+		}
+	})
+	return program.info
+}
+
+// ObjectOf returns the object an identifier denotes, or nil when the
+// identifier was not resolved.
+func (program *Program) ObjectOf(ident *ast.Ident) types.Object {
+	return program.TypesInfo().ObjectOf(ident)
+}
+
+// programImporter resolves the module's own packages from the program and
+// delegates every other import path to a source importer.
+type programImporter struct {
+	program  *Program
+	fallback types.ImporterFrom
+}
+
+func (imports *programImporter) Import(path string) (*types.Package, error) {
+	return imports.ImportFrom(path, "", 0)
+}
+
+func (imports *programImporter) ImportFrom(path, dir string, mode types.ImportMode) (*types.Package, error) {
+	// A directory may hold several package clauses when some files are
+	// excluded by build constraints; the one that builds wins
+	var err error
+	for _, pkg := range imports.program.Packages {
+		if pkg.Path == path {
+			var typesPkg *types.Package
+			if typesPkg, err = imports.check(pkg); err == nil {
+				return typesPkg, nil
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return imports.fallback.ImportFrom(path, dir, mode)
+}
+
+// check type-checks the package, including its in-package test files, once.
+func (imports *programImporter) check(pkg *Package) (*types.Package, error) {
+	switch pkg.state {
+	case checked:
+		if pkg.Types == nil {
+			return nil, fmt.Errorf("no buildable Go files in %s", pkg.Dir)
+		}
+		return pkg.Types, nil
+	case checking:
+		return nil, fmt.Errorf("import cycle through %s", pkg.Path)
+	case unchecked:
+		// Checked below
+	}
+	pkg.state = checking
+	defer func() { pkg.state = checked }()
+
+	var files []*ast.File
+	var checkedFiles []*File
+	for _, file := range pkg.Files {
+		// Files excluded by build constraints would only add duplicate declarations
+		if match, err := build.Default.MatchFile(pkg.Dir, filepath.Base(file.Path)); err != nil || !match {
+			continue
+		}
+		files = append(files, file.AST)
+		checkedFiles = append(checkedFiles, file)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no buildable Go files in %s", pkg.Dir)
+	}
+
+	config := types.Config{
+		Importer:    imports,
+		FakeImportC: true,
+		Error: func(err error) {
+			pkg.TypeErrors = append(pkg.TypeErrors, err)
+		},
+	}
+	pkg.Types, _ = config.Check(pkg.Path, imports.program.Fset, files, imports.program.info)
+	for _, file := range checkedFiles {
+		file.Checked = true
+	}
+	return pkg.Types, nil
+}
+
+// moduleCache maps directories to the module that contains them.
+type moduleCache map[string]module
+
+type module struct {
+	dir  string
+	path string
+}
+
+// importPath derives the import path of the package in dir from the nearest
+// go.mod. Without a go.mod the directory itself is used, which keeps paths
+// unique even though they cannot be imported.
+func (modules moduleCache) importPath(dir, name string) string {
+	var importPath string
+	if mod := modules.lookup(dir); mod.path != "" {
+		rel, err := filepath.Rel(mod.dir, dir)
+		if err != nil || rel == "." {
+			importPath = mod.path
+		} else {
+			importPath = path.Join(mod.path, filepath.ToSlash(rel))
+		}
+	} else {
+		importPath = filepath.ToSlash(dir)
+	}
+	if strings.HasSuffix(name, "_test") {
+		importPath += "_test"
+	}
+	return importPath
+}
+
+// lookup returns the module of dir, searching parent directories for a go.mod.
+func (modules moduleCache) lookup(dir string) module {
+	if mod, ok := modules[dir]; ok {
+		return mod
+	}
+	var mod module
+	if data, err := os.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+		mod = module{dir: dir, path: modulePath(data)}
+	} else if parent := filepath.Dir(dir); parent != dir {
+		mod = modules.lookup(parent)
+	}
+	modules[dir] = mod
+	return mod
+}
+
+// modulePath returns the path of the module directive of a go.mod file.
+func modulePath(data []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "//")
+		line = strings.TrimSpace(line)
+		if rest, ok := strings.CutPrefix(line, "module"); ok && (rest == "" || rest[0] == ' ' || rest[0] == '\t') {
+			return strings.Trim(strings.TrimSpace(rest), `"`)
+		}
+	}
+	return ""
+}
@@ -3,162 +3,166 @@ package detectors
 import (
 	"fmt"
 	"go/ast"
-	"go/parser"
 	"go/token"
-	"os"
-	"path/filepath"
-	"strings"
-
-	"github.com/Aadi-IRON/agni/config"
+	"go/types"
 )
 
-var targetMaps = []string{
-	"Messages",
-	"SuccessMessages",
-	"FailMessages",
-	"Message",
-	"BugsnagMessages",
+func init() {
+	Register(Document(
+		NewDetector(
+			"undefined-message-keys",
+			"Reports message map keys that are used but never defined",
+			CategoryMessages,
+			SeverityError,
+			DetectUnDefinedMessageKeys,
+		),
+		Explanation{
+			Rationale: `Looking up a key that is missing from the message map silently returns an
+empty string, so users see blank errors and responses. Every key looked up in
+a message catalog (Messages, SuccessMessages, FailMessages and friends, or the
+catalogs listed in the configuration) must be defined in it.`,
+			Bad:  `return errors.New(config.Messages["USER_NOT_FOUDN"])`,
+			Good: `return errors.New(config.Messages["USER_NOT_FOUND"])`,
+		},
+	))
 }
 
-func isTargetMap(name string) bool {
-	for _, m := range targetMaps {
-		if name == m {
-			return true
-		}
-	}
-	return false
+// undefinedMessageKeysOptions are the settings of the undefined-message-keys detector.
+type undefinedMessageKeysOptions struct {
+	catalogOptions
+	// Unverifiable is the severity of lookups whose key is not a constant,
+	// or "off" to leave them out.
+	Unverifiable string `json:"unverifiable"`
 }
 
-func DetectUnDefinedMessageKeys(filePath string) {
-	fmt.Println(config.CreateCompactBoxHeader("UNDEFINED MESSAGE KEYS", config.BoldPurple))
-	if filePath == "" {
-		fmt.Println("❌ Please enter a valid project folder name.")
-		return
+// Detects keys looked up in a message catalog that the catalog does not
+// define. Lookups are resolved with go/types, so each key is checked against
+// the catalog it is looked up in; in files that were not type-checked, the
+// catalogs are matched by variable name. Keys are folded like the compiler
+// does, so Messages[config.KeyUserNotFound] and Messages[prefix+"_failed"]
+// are checked when the operands are constants. Other keys are reported as
+// unverifiable, with their own severity, unless the lookup is a comma-ok one
+// that checks the key at run time.
+func DetectUnDefinedMessageKeys(pass *Pass) ([]Finding, error) {
+	options := undefinedMessageKeysOptions{Unverifiable: "info"}
+	if err := pass.DecodeOptions(&options); err != nil {
+		return nil, err
 	}
-
-	usedKeys := make(map[string]struct{})
-	definedKeys := make(map[string]struct{})
-
-	err := filepath.Walk(filePath, func(path string, info os.FileInfo, err error) error {
+	var unverifiable Severity
+	if options.Unverifiable != "off" {
+		severity, err := ParseSeverity(options.Unverifiable)
 		if err != nil {
-			fmt.Printf("Error reading file %s: %v\n", path, err)
-			return nil
-		}
-		if info.IsDir() || !strings.HasSuffix(path, ".go") {
-			return nil
+			return nil, fmt.Errorf("invalid options: unknown unverifiable %q (expected off, info, warning or error)", options.Unverifiable)
 		}
-
-		base := strings.ToLower(filepath.Base(path))
-		if base == "message.go" || base == "messages.go" {
-			CollectDefinedKeys(path, definedKeys)
-		} else {
-			CollectUsedKeys(path, usedKeys)
-		}
-		return nil
-	})
-	if err != nil {
-		fmt.Printf("Error walking files: %v\n", err)
-		return
+		unverifiable = severity
 	}
-
-	fmt.Println()
-	fmt.Println(config.BoldYellow + "🔍 Missing Keys (Used but not defined):")
-	fmt.Println()
-	missing := 0
-	for key := range usedKeys {
-		if _, found := definedKeys[key]; !found {
-			fmt.Println("-", key)
-			missing++
-		}
+	catalogs, err := findCatalogs(pass.Program, options.catalogOptions)
+	if len(catalogs) == 0 {
+		return nil, err
 	}
-	if missing == 0 {
-		fmt.Println(config.Cyan + "🎉 No missing keys! Everything is defined.")
-		return
+	byObject := make(map[types.Object]*MessageCatalog)
+	byName := make(map[string][]*MessageCatalog)
+	for _, catalog := range catalogs {
+		if catalog.object != nil {
+			byObject[catalog.object] = catalog
+		}
+		byName[catalog.Name] = append(byName[catalog.Name], catalog)
 	}
-	fmt.Println()
-}
-
-func CollectUsedKeys(filePath string, usedKeys map[string]struct{}) {
-	fset := token.NewFileSet()
-	node, err := parser.ParseFile(fset, filePath, nil, parser.AllErrors)
-	if err != nil {
-		fmt.Printf("Failed to parse %s: %v\n", filePath, err)
-		return
+	defined := make(map[*MessageCatalog]map[string]bool)
+	for _, catalog := range catalogs {
+		defined[catalog] = make(map[string]bool)
+		for _, key := range catalog.Keys {
+			defined[catalog][key.Key] = true
+		}
 	}
 
-	ast.Inspect(node, func(n ast.Node) bool {
-		idx, ok := n.(*ast.IndexExpr)
-		if !ok {
-			return true
+	info := pass.Program.TypesInfo()
+	findings, searchErr := pass.ForEachFile(pass.Program.Files, func(file *File) ([]Finding, error) {
+		if file.ParseErr != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", file.Path, file.ParseErr)
 		}
-
-		switch x := idx.X.(type) {
-		case *ast.SelectorExpr:
-			if isTargetMap(x.Sel.Name) {
-				if lit, ok := idx.Index.(*ast.BasicLit); ok && lit.Kind == token.STRING {
-					usedKeys[strings.Trim(lit.Value, `"`)] = struct{}{}
+		var found []Finding
+		// Map writes add keys at run time rather than look them up
+		written := make(map[*ast.IndexExpr]bool)
+		// Comma-ok lookups check the key at run time
+		commaOK := make(map[*ast.IndexExpr]bool)
+		ast.Inspect(file.AST, func(astNode ast.Node) bool {
+			switch node := astNode.(type) {
+			case *ast.AssignStmt:
+				if node.Tok != token.DEFINE {
+					for _, lhs := range node.Lhs {
+						if index, ok := ast.Unparen(lhs).(*ast.IndexExpr); ok {
+							written[index] = true
+						}
+					}
 				}
-			}
-		case *ast.Ident:
-			// In-package use: Messages["key"]
-			if isTargetMap(x.Name) {
-				if lit, ok := idx.Index.(*ast.BasicLit); ok && lit.Kind == token.STRING {
-					usedKeys[strings.Trim(lit.Value, `"`)] = struct{}{}
+				if len(node.Lhs) == 2 && len(node.Rhs) == 1 {
+					if index, ok := ast.Unparen(node.Rhs[0]).(*ast.IndexExpr); ok {
+						commaOK[index] = true
+					}
+				}
+			case *ast.ValueSpec:
+				if len(node.Names) == 2 && len(node.Values) == 1 {
+					if index, ok := ast.Unparen(node.Values[0]).(*ast.IndexExpr); ok {
+						commaOK[index] = true
+					}
 				}
 			}
-		}
-		return true
-	})
-}
-
-func CollectDefinedKeys(filePath string, definedKeys map[string]struct{}) {
-	fset := token.NewFileSet()
-	node, err := parser.ParseFile(fset, filePath, nil, parser.AllErrors)
-	if err != nil {
-		fmt.Printf("Failed to parse %s: %v\n", filePath, err)
-		return
-	}
-
-	ast.Inspect(node, func(n ast.Node) bool {
-		vspec, ok := n.(*ast.ValueSpec)
-		if !ok {
-			return true
-		}
-
-		// Only consider target map vars
-		target := false
-		for _, name := range vspec.Names {
-			if isTargetMap(name.Name) {
-				target = true
-				break
+			index, ok := astNode.(*ast.IndexExpr)
+			if !ok || written[index] {
+				return true
 			}
-		}
-		if !target {
-			return true
-		}
-
-		for _, value := range vspec.Values {
-			cl, ok := value.(*ast.CompositeLit)
-			if !ok {
-				continue
+			candidates := lookedUpCatalogs(file, info, index.X, byObject, byName)
+			if len(candidates) == 0 {
+				return true
 			}
-
-			// Accept map literals, named map types, selector types, or elided types
-			switch cl.Type.(type) {
-			case *ast.MapType, *ast.Ident, *ast.SelectorExpr, nil:
-				// ok
-			default:
-				// still walk keys; being permissive avoids missing aliased types
+			start, end := pass.Program.Position(index.Index.Pos()), pass.Program.Position(index.Index.End())
+			key, ok := stringValue(file, info, index.Index)
+			if !ok {
+				if unverifiable != 0 && !commaOK[index] {
+					finding := NewFinding(start, end, fmt.Sprintf("Message key '%s' of %s is unverifiable: it is not a constant", types.ExprString(index.Index), candidates[0]))
+					finding.Severity = unverifiable
+					finding.SuggestedFix = "Look the message up with a constant key, or check the key at run time"
+					found = append(found, finding)
+				}
+				return true
 			}
-
-			for _, elt := range cl.Elts {
-				if kv, ok := elt.(*ast.KeyValueExpr); ok {
-					if lit, ok := kv.Key.(*ast.BasicLit); ok && lit.Kind == token.STRING {
-						definedKeys[strings.Trim(lit.Value, `"`)] = struct{}{}
-					}
+			for _, catalog := range candidates {
+				if defined[catalog][key] {
+					return true
 				}
 			}
-		}
-		return true
+			found = append(found, NewFinding(start, end,
+				fmt.Sprintf("Message key '%s' is used but not defined in %s", key, candidates[0])))
+			return true
+		})
+		return found, nil
 	})
+	if err == nil {
+		err = searchErr
+	}
+	return findings, err
+}
+
+// lookedUpCatalogs returns the catalogs an indexed expression may denote:
+// the catalog it resolves to, or the catalogs with its name when it could
+// not be resolved.
+func lookedUpCatalogs(file *File, info *types.Info, expr ast.Expr, byObject map[types.Object]*MessageCatalog, byName map[string][]*MessageCatalog) []*MessageCatalog {
+	var ident *ast.Ident
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		// In-package use: Messages["key"]
+		ident = expr
+	case *ast.SelectorExpr:
+		ident = expr.Sel
+	default:
+		return nil
+	}
+	if object := info.Uses[ident]; file.Checked && object != nil {
+		if catalog := byObject[object]; catalog != nil {
+			return []*MessageCatalog{catalog}
+		}
+		return nil
+	}
+	return byName[ident.Name]
 }
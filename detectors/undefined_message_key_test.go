@@ -0,0 +1,119 @@
+package detectors
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Aadi-IRON/agni/config"
+)
+
+func TestDetectUnDefinedMessageKeys(t *testing.T) {
+	catalog := `package config
+
+const KeyBye = "BYE"
+
+var Messages = map[string]string{
+	"HELLO": "Hello",
+	KeyBye:  "Bye",
+	"SAVE_FAILED": "Could not save",
+}
+`
+	tests := []struct {
+		name    string
+		source  string
+		options map[string]any
+		want    []string
+	}{
+		{
+			name: "constant keys",
+			source: `package app
+
+import "example.com/app/config"
+
+const prefix = "SAVE"
+
+func Use() []string {
+	return []string{
+		config.Messages["HELLO"],
+		config.Messages[config.KeyBye],
+		config.Messages[prefix+"_FAILED"],
+		config.Messages["HELO"],
+		config.Messages[prefix+"_DONE"],
+	}
+}
+`,
+			want: []string{
+				"app.go:12 error: Message key 'HELO' is used but not defined in config.Messages",
+				"app.go:13 error: Message key 'SAVE_DONE' is used but not defined in config.Messages",
+			},
+		},
+		{
+			name: "unverifiable keys",
+			source: `package app
+
+import "example.com/app/config"
+
+func Use(key string) string {
+	return config.Messages[key]
+}
+`,
+			want: []string{"app.go:6 info: Message key 'key' of config.Messages is unverifiable: it is not a constant"},
+		},
+		{
+			name: "unverifiable severity",
+			source: `package app
+
+import "example.com/app/config"
+
+func Use(key string) string {
+	return config.Messages[key]
+}
+`,
+			options: map[string]any{"unverifiable": "warning"},
+			want:    []string{"app.go:6 warning: Message key 'key' of config.Messages is unverifiable: it is not a constant"},
+		},
+		{
+			name: "unverifiable keys turned off",
+			source: `package app
+
+import "example.com/app/config"
+
+func Use(key string) string {
+	return config.Messages[key]
+}
+`,
+			options: map[string]any{"unverifiable": "off"},
+			want:    []string{},
+		},
+		{
+			name: "comma-ok lookups and map writes",
+			source: `package app
+
+import "example.com/app/config"
+
+func Use(key string) string {
+	message, ok := config.Messages[key]
+	var other, found = config.Messages[key+"_OTHER"]
+	config.Messages["ADDED"] = "Added"
+	if ok && found {
+		return message + other
+	}
+	return ""
+}
+`,
+			want: []string{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			project := &config.Project{Detectors: map[string]config.DetectorConfig{
+				"undefined-message-keys": {Options: test.options},
+			}}
+			files := map[string]string{"config/messages.go": catalog, "app.go": test.source}
+			root, results := runDetectors(t, files, project, "undefined-message-keys")
+			if got := describeFindings(root, results); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("findings = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
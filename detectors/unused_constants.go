@@ -1,133 +1,291 @@
 package detectors
 
 import (
-	"bufio"
 	"fmt"
-	"os"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
 	"path/filepath"
-	"strings"
-
-	"github.com/Aadi-IRON/agni/config"
 )
 
+func init() {
+	Register(Document(
+		NewDetector(
+			"unused-constants",
+			"Reports constants that are not referenced anywhere in the module",
+			CategoryUnused,
+			SeverityWarning,
+			DetectUnusedConstants,
+		),
+		Explanation{
+			Rationale: `Constants that nothing refers to any more are noise: they suggest behavior
+that no longer exists and make shared constants files harder to scan. Every
+package-level and local constant of the module is checked, and a constant is
+used only when an identifier resolves to it, so StatusOK does not keep Status
+alive. Values of enum-style types, such as typed iota groups, are reported as
+enum values, except the zero value of an iota group, which zeroed variables
+hold without naming it. Delete constants once their last use is gone.`,
+			Bad: `const (
+	StatusActive   = "active"
+	StatusArchived = "archived" // nothing uses this any more
+)`,
+			Good: `const (
+	StatusActive = "active"
+)`,
+		},
+	))
+}
+
+// ConstInfo describes a declared constant.
+type ConstInfo struct {
+	Name     string
+	FilePath string
+	Line     int
+	Column   int
+	// Local is set for constants declared inside a function.
+	Local bool
+
+	ident   *ast.Ident
+	genDecl *ast.GenDecl
+	spec    *ast.ValueSpec
+}
+
+// unusedConstantsOptions are the settings of the unused-constants detector.
+type unusedConstantsOptions struct {
+	// Files limits the report to constants declared in these files, relative
+	// to the root. Every file of the module is checked when empty.
+	Files []string `json:"files"`
+	// Exported reports exported package-level constants too. Libraries whose
+	// constants are used by other modules can turn it off.
+	Exported bool `json:"exported"`
+}
+
 // Detects all unused constants present in the directory.
-func DetectUnusedConstants(filePath string) {
-	fmt.Println(config.CreateCompactBoxHeader("UNUSED CONSTANTS", config.BoldGreen))
-	fmt.Println()
-	fmt.Println(config.BoldYellow + "🔍 Detecting unused constants (Declared but not used):")
-	if filePath == "" {
-		fmt.Println("Please pass a valid directory path.")
-		return
+func DetectUnusedConstants(pass *Pass) ([]Finding, error) {
+	options := unusedConstantsOptions{Exported: true}
+	if err := pass.DecodeOptions(&options); err != nil {
+		return nil, err
 	}
 	// Find unused constants
-	unusedConsts, err := FindUnusedConsts(filePath)
-	if err != nil {
-		fmt.Println("Error:", err)
-		return
-	}
-	// Output results
-	if len(unusedConsts) == 0 {
-		fmt.Println()
-		fmt.Println(config.BoldGreen + "✅  All constants in const.go are used in the project.")
-		fmt.Println()
-		return
-	}
-	fmt.Println()
-	fmt.Println(config.BoldYellow + "Unused constants in const.go:-> ")
+	unusedConsts := FindUnusedConsts(pass.Program, options.Files)
+	enums := findEnums(pass.Program)
+	info := pass.Program.TypesInfo()
+	var findings []Finding
 	for _, constant := range unusedConsts {
-		fmt.Printf(config.Red+"- %s\n", constant)
-	}
-	fmt.Println()
-}
-
-// SearchConstantInProject checks if a constant is used in any `.go` file.
-func SearchConstantInProject(projectDir, constant, excludeFile string) (bool, error) {
-	found := false
-	err := filepath.Walk(projectDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
+		if !options.Exported && constant.ident.IsExported() && !constant.Local {
+			continue
 		}
-		// Skip directories; keep all .go files, including the const file.
-		if info.IsDir() || !strings.HasSuffix(path, ".go") {
-			return nil
+		position := token.Position{Filename: constant.FilePath, Line: constant.Line, Column: constant.Column}
+		message := fmt.Sprintf("Constant '%s' is declared but not used", constant.Name)
+		if object, ok := info.Defs[constant.ident].(*types.Const); ok && enums[enumTypeName(object.Type())] != nil {
+			if isIotaZero(object, constant) {
+				continue
+			}
+			message = fmt.Sprintf("Value '%s' of enum %s is declared but not used", constant.Name, enumTypeName(object.Type()).Name())
 		}
-
-		file, err := os.Open(path)
-		if err != nil {
-			return err
+		finding := NewFinding(position, pass.Program.Position(constant.ident.End()), message)
+		if file := pass.Program.File(constant.FilePath); file != nil {
+			finding.Edits = deleteConstEdits(file, pass.Program.Fset, constant.genDecl, constant.spec)
 		}
-		defer file.Close()
+		findings = append(findings, finding)
+	}
+	return findings, nil
+}
 
-		scanner := bufio.NewScanner(file)
-		for scanner.Scan() {
-			line := scanner.Text()
-			if strings.Contains(line, constant) {
-				found = true
-				return nil // Found, stop scanning this file
-			}
+// isIotaZero reports whether the constant is the zero value opening an iota
+// group, such as "Unknown State = iota".
+func isIotaZero(object *types.Const, declared ConstInfo) bool {
+	if declared.genDecl.Specs[0] != declared.spec || len(declared.spec.Values) == 0 {
+		return false
+	}
+	if value := object.Val(); value.Kind() != constant.Int || constant.Sign(value) != 0 {
+		return false
+	}
+	usesIota := false
+	ast.Inspect(declared.spec.Values[0], func(astNode ast.Node) bool {
+		if ident, ok := astNode.(*ast.Ident); ok && ident.Name == "iota" {
+			usesIota = true
 		}
-		return nil
+		return !usesIota
 	})
-	return found, err
+	return usesIota
 }
 
-// FindUnusedConstants finds constants in `const.go` that are not used anywhere in the project.
-func FindUnusedConsts(filePath string) ([]string, error) {
-	constants, err := ExtractConstsFromFile(filePath + "/config/const.go")
-	excludeFile := "const.go"
-	if err != nil {
-		constants, err = ExtractConstsFromFile(filePath + "/config/Const.go")
-		excludeFile = "Const.go"
-		if err != nil {
-			return nil, err
+// FindUnusedConsts finds the package-level and local constants that no
+// identifier of the module refers to. When files is not empty, only the
+// constants declared in those files, relative to the root, are returned.
+//
+// References are resolved with go/types. Identifiers in files that could
+// not be type-checked, such as files excluded by build constraints, keep
+// every constant with the same name alive, and so does any identifier for
+// a constant declared in such a file.
+func FindUnusedConsts(program *Program, files []string) []ConstInfo {
+	info := program.TypesInfo()
+	selected := make(map[string]bool)
+	for _, name := range files {
+		selected[filepath.Join(program.Root, name)] = true
+	}
+
+	var constants []ConstInfo
+	uncheckedNames := make(map[string]bool)
+	for _, file := range program.Files {
+		if file.AST == nil {
+			continue
+		}
+		declared := ExtractConstsFromFile(program, file)
+		if !file.Checked {
+			for _, name := range identNames(file.AST, declared) {
+				uncheckedNames[name] = true
+			}
+		}
+		if len(selected) == 0 || selected[file.Path] {
+			constants = append(constants, declared...)
 		}
 	}
-	var unusedConsts []string
+
+	// Without type information, fall back to identifier names: still
+	// whole identifiers, never substrings
+	var allNames map[string]bool
+	var unusedConsts []ConstInfo
 	for _, constant := range constants {
-		used, err := SearchConstantInProject(filePath, constant, excludeFile)
-		if err != nil {
-			return nil, err
+		if uncheckedNames[constant.Name] {
+			continue
 		}
-		if !used {
-			unusedConsts = append(unusedConsts, constant)
+		file := program.File(constant.FilePath)
+		if object := info.Defs[constant.ident]; file.Checked && object != nil {
+			if len(program.References(object)) > 1 {
+				continue
+			}
+		} else {
+			if allNames == nil {
+				allNames = make(map[string]bool)
+				for _, other := range program.Files {
+					if other.AST != nil {
+						for _, name := range identNames(other.AST, ExtractConstsFromFile(program, other)) {
+							allNames[name] = true
+						}
+					}
+				}
+			}
+			if allNames[constant.Name] {
+				continue
+			}
 		}
+		unusedConsts = append(unusedConsts, constant)
 	}
-	return unusedConsts, nil
+	return unusedConsts
 }
 
-// ExtractConstantsFromFile reads `const.go` and extracts constants defined in it.
-func ExtractConstsFromFile(filePath string) ([]string, error) {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return nil, err
+// ExtractConstsFromFile returns the constants declared in a file, at package
+// level and inside functions. Blank constants are left out.
+func ExtractConstsFromFile(program *Program, file *File) []ConstInfo {
+	packageLevel := make(map[ast.Decl]bool)
+	for _, decl := range file.AST.Decls {
+		packageLevel[decl] = true
 	}
-	defer file.Close()
-	var constants []string
-	scanner := bufio.NewScanner(file)
-	constBlock := false // Track if inside a `const` block
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		// Detect start of a `const` block
-		if strings.HasPrefix(line, "const (") {
-			constBlock = true
-			continue
+	var constants []ConstInfo
+	ast.Inspect(file.AST, func(astNode ast.Node) bool {
+		genDecl, ok := astNode.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.CONST {
+			return true
 		}
-		// Detect end of a `const` block
-		if constBlock && line == ")" {
-			constBlock = false
-			continue
+		for _, spec := range genDecl.Specs {
+			valueSpec := spec.(*ast.ValueSpec)
+			for _, name := range valueSpec.Names {
+				if name.Name == "_" {
+					continue
+				}
+				position := program.Position(name.Pos())
+				constants = append(constants, ConstInfo{
+					Name:     name.Name,
+					FilePath: file.Path,
+					Line:     position.Line,
+					Column:   position.Column,
+					Local:    !packageLevel[genDecl],
+					ident:    name,
+					genDecl:  genDecl,
+					spec:     valueSpec,
+				})
+			}
 		}
-		// Extract constant names from the block
-		if constBlock {
-			// Assume constant names are the first word before the `=` sign
-			parts := strings.Fields(line)
-			if len(parts) > 0 {
-				constants = append(constants, parts[0])
+		return false
+	})
+	return constants
+}
+
+// identNames returns the names of the identifiers of a file other than the
+// names the given constants declare.
+func identNames(file *ast.File, declared []ConstInfo) []string {
+	definitions := make(map[*ast.Ident]bool)
+	for _, constant := range declared {
+		definitions[constant.ident] = true
+	}
+	var names []string
+	ast.Inspect(file, func(astNode ast.Node) bool {
+		if ident, ok := astNode.(*ast.Ident); ok && !definitions[ident] {
+			names = append(names, ident.Name)
+		}
+		return true
+	})
+	return names
+}
+
+// deleteConstEdits deletes the declaration of a constant, with its doc and
+// line comments. It returns nil when deleting could change other constants:
+// the constant shares its spec with other names, or its group relies on iota
+// or implicit repetition of the previous expression.
+func deleteConstEdits(file *File, fset *token.FileSet, genDecl *ast.GenDecl, valueSpec *ast.ValueSpec) []TextEdit {
+	if len(valueSpec.Names) != 1 {
+		return nil
+	}
+	if !genDecl.Lparen.IsValid() {
+		// A single "const X = ..." goes away as a whole
+		return deleteNodeEdits(file, fset, genDecl.Doc, genDecl, nil)
+	}
+	if usesIota(genDecl) {
+		return nil
+	}
+	return deleteNodeEdits(file, fset, valueSpec.Doc, valueSpec, valueSpec.Comment)
+}
+
+// usesIota reports whether the values of a const group depend on their
+// position: some spec mentions iota or repeats the previous expression.
+func usesIota(genDecl *ast.GenDecl) bool {
+	for _, spec := range genDecl.Specs {
+		valueSpec := spec.(*ast.ValueSpec)
+		if len(valueSpec.Values) == 0 {
+			return true
+		}
+		for _, value := range valueSpec.Values {
+			found := false
+			ast.Inspect(value, func(astNode ast.Node) bool {
+				if ident, ok := astNode.(*ast.Ident); ok && ident.Name == "iota" {
+					found = true
+				}
+				return !found
+			})
+			if found {
+				return true
 			}
 		}
 	}
-	if err := scanner.Err(); err != nil {
-		return nil, err
+	return false
+}
+
+// deleteNodeEdits deletes the lines of node together with its doc comment
+// and trailing line comment.
+func deleteNodeEdits(file *File, fset *token.FileSet, doc *ast.CommentGroup, node ast.Node, comment *ast.CommentGroup) []TextEdit {
+	start, end := node.Pos(), node.End()
+	if doc != nil {
+		start = doc.Pos()
+	}
+	if comment != nil {
+		end = comment.End()
+	}
+	edit, ok := deleteLinesEdit(file.Path, file.Src, fset.Position(start).Offset, fset.Position(end).Offset)
+	if !ok {
+		return nil
 	}
-	return constants, nil
+	return []TextEdit{edit}
 }
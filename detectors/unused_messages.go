@@ -1,160 +1,105 @@
 package detectors
 
 import (
-	"bufio"
 	"fmt"
 	"go/ast"
-	"go/parser"
 	"go/token"
-	"os"
-	"path/filepath"
-	"strings"
-
-	"github.com/Aadi-IRON/agni/config"
+	"go/types"
+	"sync"
 )
 
-// Detects unused messages throughout the directory.
-func DetectUnusedMessages(filePath string) {
-	fmt.Println(config.CreateCompactBoxHeader("UNUSED MESSAGES", config.BoldCyan))
-	fmt.Println()
-	if filePath == "" {
-		fmt.Println("Please pass a valid directory path. ")
-		return
-	}
-	fmt.Println(config.BoldYellow + "🔍 Detecting unused messages (Declared but not used):")
-	fmt.Println()
-	// Extract all keys from the Messages map in message.go
-	keys, err := ExtractKeysFromMessages(filePath + "/config/message.go")
-	messageFileName := "message.go"
-	if err != nil {
-		keys, err = ExtractKeysFromMessages(filePath + "/config/Message.go")
-		messageFileName = "Message.go"
-		if err != nil {
-			keys, err = ExtractKeysFromMessages(filePath + "/config/messages.go")
-			messageFileName = "messages.go"
-			if err != nil {
-				keys, err = ExtractKeysFromMessages(filePath + "/config/Messages.go")
-				messageFileName = "Messages.go"
-				if err != nil {
-					fmt.Println("Error occurred while extracting keys", err)
-					return
-				}
-			}
-		}
-	}
-	// Check if each key is used in the project
-	var unusedKeys []string
-	for _, key := range keys {
-		found, err := SearchKeyInProject(filePath, key, messageFileName)
-		if err != nil {
-			fmt.Println("Error searching for key in the project:", err)
-			return
-		}
-		if !found {
-			unusedKeys = append(unusedKeys, key)
-		}
-	}
-	// Print results
-	if len(unusedKeys) == 0 {
-		fmt.Println(config.BoldGreen + "✅  All keys in messages.go file are used in the project.")
-	} else {
-		fmt.Println()
-		fmt.Println(config.BoldYellow + "Unused keys in messages.go file:-> ")
-		for _, key := range unusedKeys {
-			fmt.Println(config.Red+"- ", key)
-		}
-	}
-	fmt.Println()
+func init() {
+	Register(Document(
+		NewDetector(
+			"unused-messages",
+			"Reports keys of the message catalogs that are not used anywhere in the project",
+			CategoryMessages,
+			SeverityInfo,
+			DetectUnusedMessages,
+		),
+		Explanation{
+			Rationale: `Every key in the message catalog should be shown to a user somewhere. Keys
+that are no longer looked up are dead translations that still have to be
+maintained and reviewed.`,
+			Bad: `var Messages = map[string]string{
+	"USER_CREATED": "User created",
+	"USER_BANNED":  "User banned", // never looked up
+}`,
+			Good: `var Messages = map[string]string{
+	"USER_CREATED": "User created",
+}`,
+		},
+	))
 }
 
-// SearchKeyInProject searches for a specific key across all .go files in the project
-func SearchKeyInProject(rootDir, searchKey string, messageFileName string) (bool, error) {
-	found := false
-
-	// Walk through all files in the project
-	err := filepath.Walk(rootDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		condition := !info.IsDir() && strings.HasSuffix(info.Name(), ".go") && !strings.HasSuffix(path, messageFileName)
-		// Process only .go files, excluding the message.go file itself
-		if condition {
-			fileFound, err := SearchKeyInFile(path, searchKey)
-			if err != nil {
-				return err
-			}
-			if fileFound {
-				found = true
-			}
-		}
-		return nil
-	})
-
-	return found, err
+// unusedMessagesOptions are the settings of the unused-messages detector.
+type unusedMessagesOptions struct {
+	catalogOptions
 }
 
-// searchKeyInFile searches for a key in a specific .go file
-func SearchKeyInFile(filePath, searchKey string) (bool, error) {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return false, err
-	}
-	defer file.Close()
-	scanner := bufio.NewScanner(file)
-	// Check each line for the search key
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.Contains(line, searchKey) {
-			return true, nil
-		}
+// Detects unused messages throughout the directory. A key is used when a
+// string constant with its value appears anywhere outside the catalogs:
+// a literal, or an expression such as prefix+"_failed" that folds to it.
+func DetectUnusedMessages(pass *Pass) ([]Finding, error) {
+	var options unusedMessagesOptions
+	if err := pass.DecodeOptions(&options); err != nil {
+		return nil, err
 	}
-
-	return false, scanner.Err()
-}
-
-// ExtractKeysFromMessages extracts all keys from the Messages map in message.go
-func ExtractKeysFromMessages(messageFilePath string) ([]string, error) {
-	var keys []string
-
-	// Read and parse the message.go file
-	functionSet := token.NewFileSet()
-	node, err := parser.ParseFile(functionSet, messageFilePath, nil, parser.AllErrors)
-	if err != nil {
-		fmt.Println("1")
+	catalogs, err := findCatalogs(pass.Program, options.catalogOptions)
+	if len(catalogs) == 0 {
+		// The project has no message catalog, so there is nothing to check
 		return nil, err
 	}
+	literals := make(map[*ast.CompositeLit]bool)
+	for _, catalog := range catalogs {
+		literals[catalog.literal] = true
+	}
 
-	// Traverse the AST to extract keys from the Messages map
-	for _, decl := range node.Decls {
-		genDecl, ok := decl.(*ast.GenDecl)
-		if !ok {
-			continue
+	used := make(map[string]bool)
+	var mutex sync.Mutex
+	_, searchErr := pass.ForEachFile(pass.Program.Files, func(file *File) ([]Finding, error) {
+		fileKeys := SearchKeysInFile(file, pass.Program.TypesInfo(), literals)
+		mutex.Lock()
+		for key := range fileKeys {
+			used[key] = true
 		}
+		mutex.Unlock()
+		return nil, nil
+	})
+	if err == nil {
+		err = searchErr
+	}
 
-		for _, spec := range genDecl.Specs {
-			valueSpec, ok := spec.(*ast.ValueSpec)
-			if !ok {
-				continue
+	var findings []Finding
+	for _, catalog := range catalogs {
+		for _, key := range catalog.Keys {
+			if !used[key.Key] {
+				findings = append(findings, NewFinding(key.Position, token.Position{},
+					fmt.Sprintf("Message key '%s' is defined in %s but never used", key.Key, catalog)))
 			}
+		}
+	}
+	return findings, err
+}
 
-			for _, value := range valueSpec.Values {
-				compLit, ok := value.(*ast.CompositeLit)
-				if !ok {
-					continue
-				}
-
-				// Check if the map is named "Messages"
-				for _, elt := range compLit.Elts {
-					kvExpr, ok := elt.(*ast.KeyValueExpr)
-					if !ok {
-						continue
-					}
-
-					key := strings.Trim(kvExpr.Key.(*ast.BasicLit).Value, `"`)
-					keys = append(keys, key)
-				}
+// SearchKeysInFile returns the values of the constant string expressions of
+// a file, leaving out the catalog literals themselves. Without type
+// information only string literals are known.
+func SearchKeysInFile(file *File, info *types.Info, catalogs map[*ast.CompositeLit]bool) map[string]bool {
+	keys := make(map[string]bool)
+	if file.AST == nil {
+		return keys
+	}
+	ast.Inspect(file.AST, func(astNode ast.Node) bool {
+		switch node := astNode.(type) {
+		case *ast.CompositeLit:
+			return !catalogs[node]
+		case ast.Expr:
+			if key, ok := stringValue(file, info, node); ok {
+				keys[key] = true
 			}
 		}
-	}
-	return keys, nil
+		return true
+	})
+	return keys
 }
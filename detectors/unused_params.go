@@ -3,89 +3,103 @@ package detectors
 import (
 	"fmt"
 	"go/ast"
-	"go/parser"
 	"go/token"
-	"os"
-	"path/filepath"
-	"strings"
-
-	"github.com/Aadi-IRON/agni/config"
+	"go/types"
 )
 
-// Detects unused params throughout the project.
-func DetectUnusedParams(filePath string) {
-	fmt.Println(config.CreateCompactBoxHeader("UNUSED PARAMETERS", config.BoldYellow))
-	fmt.Println()
-	fmt.Println(config.BoldYellow + "🔍 Detecting unused function parameters :- ")
-	fmt.Println()
-	if filePath == "" {
-		fmt.Println("Please pass a valid directory path. ")
-		return
-	}
-	if err := ProcessDirectory(filePath); err != nil {
-		fmt.Println("Error occurred :", err)
-	}
-	fmt.Println()
+func init() {
+	Register(Document(
+		NewDetector(
+			"unused-params",
+			"Reports function parameters that are never used in the function body",
+			CategoryUnused,
+			SeverityWarning,
+			DetectUnusedParams,
+		),
+		Explanation{
+			Rationale: `Parameters that are never read make a function harder to understand: readers
+have to work out whether the value matters, and callers keep computing values
+that are thrown away. Either remove the parameter or, when the signature is
+fixed by an interface or callback type, rename it to _ to make the intent clear.`,
+			Bad: `func SendMail(to string, subject string, retries int) error {
+	return mailer.Send(to, subject)
+}`,
+			Good: `func SendMail(to string, subject string) error {
+	return mailer.Send(to, subject)
+}`,
+		},
+	))
 }
 
-// ProcessDirectory processes all .go files in the specified directory.
-func ProcessDirectory(dir string) error {
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+// Detects unused params throughout the project.
+func DetectUnusedParams(pass *Pass) ([]Finding, error) {
+	// Process all .go files of the program
+	return pass.ForEachFile(pass.Program.Files, func(file *File) ([]Finding, error) {
+		findings, err := CheckUnusedVars(file, pass.Program)
 		if err != nil {
-			return fmt.Errorf("error walking through directory: %v", err)
-		}
-		// Process only Go files
-		if !info.IsDir() && strings.HasSuffix(info.Name(), ".go") {
-			if err := CheckUnusedVars(path); err != nil {
-				fmt.Printf("Error processing file '%s': %v\n", path, err)
-			}
+			return findings, fmt.Errorf("error processing file '%s': %v", file.Path, err)
 		}
-		return nil
+		return findings, nil
 	})
-	return err
-}
-func GetNode(fileName string) (node *ast.File, err error) {
-	// Open the Go file
-	file, err := os.Open(fileName)
-	if err != nil {
-		return node, err
-	}
-	defer file.Close()
-	// Create a new scanner/tokenizer for the file
-	fileSet := token.NewFileSet()
-	// Parse the file into an AST (Abstract Syntax Tree)
-	node, err = parser.ParseFile(fileSet, fileName, file, parser.AllErrors)
-	if err != nil {
-		return node, err
-	}
-	return node, err
 }
 
 // Checks for unused variables in a Go file, considering arguments in function calls.
-func CheckUnusedVars(fileName string) error {
-	node, err := GetNode(fileName)
-	if err != nil {
-		return fmt.Errorf("error while opening/parsing file: %v", err)
+func CheckUnusedVars(file *File, program *Program) ([]Finding, error) {
+	if file.ParseErr != nil {
+		return nil, fmt.Errorf("error while opening/parsing file: %v", file.ParseErr)
 	}
+	// Without type information identifiers are matched by name
+	var info *types.Info
+	if typesInfo := program.TypesInfo(); file.Checked {
+		info = typesInfo
+	}
+	var findings []Finding
 	// Analyze function declarations in the file
-	ast.Inspect(node, func(astNode ast.Node) bool {
+	ast.Inspect(file.AST, func(astNode ast.Node) bool {
 		if function, ok := astNode.(*ast.FuncDecl); ok {
-			AnalyzeFunc(function, fileName)
+			findings = append(findings, AnalyzeFunc(function, program.Fset, info)...)
 		}
 		return true
 	})
-	return nil
+	return findings, nil
 }
 
-// Inspects a function's parameters and body for unused variables.
-func AnalyzeFunc(function *ast.FuncDecl, fileName string) {
+// Inspects a function's parameters and body for unused variables. When info
+// is not nil, identifiers are resolved to the objects they denote, so a
+// shadowing variable of the same name does not count as a use.
+func AnalyzeFunc(function *ast.FuncDecl, fileSet *token.FileSet, info *types.Info) []Finding {
+	// Functions without a body (assembly or linkname stubs) have nothing to inspect
+	if function.Body == nil {
+		return nil
+	}
 	varUsed := InitializeVarUsage(function.Type.Params)
+	params := make(map[string]types.Object)
+	if info != nil {
+		for _, param := range function.Type.Params.List {
+			for _, paramName := range param.Names {
+				if object := info.Defs[paramName]; object != nil {
+					params[paramName.Name] = object
+				}
+			}
+		}
+	}
 	// Mark variables as used if they appear in the function body
-	MarkUsedVars(function.Body, varUsed)
-	// Collect and print unused variables
-	if unusedVars := GetUnusedVars(varUsed); len(unusedVars) > 0 {
-		fmt.Printf(config.Yellow+"File '%s':"+config.Purple+" Function '%s' has unused variables: "+config.Red+"%s\n", fileName, function.Name.Name, strings.Join(unusedVars, ", "))
+	MarkUsedVars(function.Body, varUsed, info, params)
+	// Report every unused parameter at its declaration
+	var findings []Finding
+	for _, param := range function.Type.Params.List {
+		for _, paramName := range param.Names {
+			if used, tracked := varUsed[paramName.Name]; !tracked || used {
+				continue
+			}
+			finding := NewFinding(fileSet.Position(paramName.Pos()), fileSet.Position(paramName.End()),
+				fmt.Sprintf("Function '%s' has unused parameter '%s'", function.Name.Name, paramName.Name))
+			finding.SuggestedFix = fmt.Sprintf("Remove '%s' or rename it to '_'", paramName.Name)
+			finding.Edits = []TextEdit{identEdit(fileSet, paramName, "_")}
+			findings = append(findings, finding)
+		}
 	}
+	return findings
 }
 
 // Initializes a map to track variable usage for function parameters.
@@ -96,14 +110,19 @@ func InitializeVarUsage(params *ast.FieldList) map[string]bool {
 	}
 	for _, param := range params.List {
 		for _, paramName := range param.Names {
+			// Blank parameters are unused on purpose
+			if paramName.Name == "_" {
+				continue
+			}
 			varUsed[paramName.Name] = false
 		}
 	}
 	return varUsed
 }
 
-// Inspects a function body to mark variables as used.
-func MarkUsedVars(body *ast.BlockStmt, varUsed map[string]bool) {
+// Inspects a function body to mark variables as used. With type information
+// an identifier only counts when it refers to the parameter object in params.
+func MarkUsedVars(body *ast.BlockStmt, varUsed map[string]bool, info *types.Info, params map[string]types.Object) {
 	if body == nil {
 		return
 	}
@@ -111,26 +130,40 @@ func MarkUsedVars(body *ast.BlockStmt, varUsed map[string]bool) {
 		switch node := astNode.(type) {
 		case *ast.Ident:
 			// Mark identifiers as used
-			if _, exists := varUsed[node.Name]; exists {
-				varUsed[node.Name] = true
-			}
+			markUsed(node, varUsed, info, params)
 		case *ast.CallExpr:
 			// Check arguments in function calls
-			MarkArgsAsUsed(node.Args, varUsed)
+			MarkArgsAsUsed(node.Args, varUsed, info, params)
 		}
 		return true
 	})
 }
 
 // Marks variables used as arguments in function calls.
-func MarkArgsAsUsed(args []ast.Expr, varUsed map[string]bool) {
+func MarkArgsAsUsed(args []ast.Expr, varUsed map[string]bool, info *types.Info, params map[string]types.Object) {
 	for _, arg := range args {
 		if ident, ok := arg.(*ast.Ident); ok {
-			if _, exists := varUsed[ident.Name]; exists {
-				varUsed[ident.Name] = true
-			}
+			markUsed(ident, varUsed, info, params)
+		}
+	}
+}
+
+// markUsed marks the parameter an identifier refers to as used.
+func markUsed(ident *ast.Ident, varUsed map[string]bool, info *types.Info, params map[string]types.Object) {
+	if _, exists := varUsed[ident.Name]; !exists {
+		return
+	}
+	if param, resolved := params[ident.Name]; resolved {
+		// Declarations, fields and shadowing variables share the spelling only
+		if _, declares := info.Defs[ident]; declares {
+			return
+		}
+		// Unresolved identifiers (type errors) count as uses to stay quiet
+		if object := info.Uses[ident]; object != nil && object != param {
+			return
 		}
 	}
+	varUsed[ident.Name] = true
 }
 
 // Collects variable names that were never marked as used.
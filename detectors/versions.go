@@ -0,0 +1,162 @@
+package detectors
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// semver is a parsed semantic version such as v1.4.2 or v2.0.0-rc.1.
+type semver struct {
+	major, minor, patch int
+	prerelease          []string
+}
+
+// parseSemver parses a version with or without its leading "v". Minor and
+// patch numbers may be left out, and build metadata is ignored.
+func parseSemver(text string) (semver, error) {
+	rest := strings.TrimPrefix(text, "v")
+	rest, _, _ = strings.Cut(rest, "+")
+	rest, prerelease, hasPrerelease := strings.Cut(rest, "-")
+	parts := strings.Split(rest, ".")
+	if len(parts) > 3 {
+		return semver{}, fmt.Errorf("invalid version %q", text)
+	}
+	var numbers [3]int
+	for idx, part := range parts {
+		number, err := strconv.Atoi(part)
+		if err != nil || number < 0 || (len(part) > 1 && part[0] == '0') {
+			return semver{}, fmt.Errorf("invalid version %q", text)
+		}
+		numbers[idx] = number
+	}
+	version := semver{major: numbers[0], minor: numbers[1], patch: numbers[2]}
+	if hasPrerelease {
+		if prerelease == "" {
+			return semver{}, fmt.Errorf("invalid version %q", text)
+		}
+		version.prerelease = strings.Split(prerelease, ".")
+	}
+	return version, nil
+}
+
+// compare returns -1, 0 or +1 as version sorts before, with or after other,
+// following the precedence rules of semantic versioning. Go pseudo-versions
+// are pre-releases, so they sort before the release they precede.
+func (version semver) compare(other semver) int {
+	for _, pair := range [][2]int{{version.major, other.major}, {version.minor, other.minor}, {version.patch, other.patch}} {
+		if pair[0] != pair[1] {
+			return compareInts(pair[0], pair[1])
+		}
+	}
+	switch {
+	case len(version.prerelease) == 0 && len(other.prerelease) == 0:
+		return 0
+	case len(version.prerelease) == 0:
+		return 1
+	case len(other.prerelease) == 0:
+		return -1
+	}
+	for idx := 0; idx < len(version.prerelease) && idx < len(other.prerelease); idx++ {
+		left, right := version.prerelease[idx], other.prerelease[idx]
+		if left == right {
+			continue
+		}
+		leftNumber, leftErr := strconv.Atoi(left)
+		rightNumber, rightErr := strconv.Atoi(right)
+		switch {
+		case leftErr == nil && rightErr == nil:
+			return compareInts(leftNumber, rightNumber)
+		case leftErr == nil:
+			// Numeric identifiers sort before alphanumeric ones
+			return -1
+		case rightErr == nil:
+			return 1
+		}
+		return strings.Compare(left, right)
+	}
+	return compareInts(len(version.prerelease), len(other.prerelease))
+}
+
+func compareInts(left, right int) int {
+	switch {
+	case left < right:
+		return -1
+	case left > right:
+		return 1
+	}
+	return 0
+}
+
+// versionConstraint is a single comparison such as ">=1.2.0".
+type versionConstraint struct {
+	operator string
+	version  semver
+}
+
+// versionRange is a set of alternatives separated by "||", each of which
+// is a list of constraints separated by commas or spaces that must all hold:
+// ">=1.0.0, <1.4.2 || 2.0.0-rc.1".
+type versionRange [][]versionConstraint
+
+// parseVersionRange parses a version range. A version without an operator
+// must match exactly.
+func parseVersionRange(text string) (versionRange, error) {
+	var ranges versionRange
+	for _, alternative := range strings.Split(text, "||") {
+		var constraints []versionConstraint
+		for _, field := range strings.Fields(strings.ReplaceAll(alternative, ",", " ")) {
+			operator := ""
+			for _, candidate := range []string{">=", "<=", "!=", ">", "<", "="} {
+				if strings.HasPrefix(field, candidate) {
+					operator = candidate
+					break
+				}
+			}
+			version, err := parseSemver(strings.TrimPrefix(field, operator))
+			if err != nil {
+				return nil, fmt.Errorf("invalid version range %q: %v", text, err)
+			}
+			if operator == "" {
+				operator = "="
+			}
+			constraints = append(constraints, versionConstraint{operator: operator, version: version})
+		}
+		if len(constraints) == 0 {
+			return nil, fmt.Errorf("invalid version range %q: empty alternative", text)
+		}
+		ranges = append(ranges, constraints)
+	}
+	return ranges, nil
+}
+
+// Contains reports whether version satisfies the range.
+func (ranges versionRange) Contains(version semver) bool {
+	for _, constraints := range ranges {
+		satisfied := true
+		for _, constraint := range constraints {
+			order := version.compare(constraint.version)
+			switch constraint.operator {
+			case "=":
+				satisfied = order == 0
+			case "!=":
+				satisfied = order != 0
+			case ">":
+				satisfied = order > 0
+			case ">=":
+				satisfied = order >= 0
+			case "<":
+				satisfied = order < 0
+			case "<=":
+				satisfied = order <= 0
+			}
+			if !satisfied {
+				break
+			}
+		}
+		if satisfied {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+package detectors
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSemver(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    semver
+		wantErr bool
+	}{
+		{text: "v1.4.2", want: semver{major: 1, minor: 4, patch: 2}},
+		{text: "1.4.2", want: semver{major: 1, minor: 4, patch: 2}},
+		{text: "v2", want: semver{major: 2}},
+		{text: "v2.1", want: semver{major: 2, minor: 1}},
+		{text: "v2.0.0-rc.1", want: semver{major: 2, prerelease: []string{"rc", "1"}}},
+		{text: "v1.0.0+build.5", want: semver{major: 1}},
+		{text: "v1.0.0-beta+exp.sha", want: semver{major: 1, prerelease: []string{"beta"}}},
+		{
+			text: "v0.0.0-20240101120000-abcdef123456",
+			want: semver{prerelease: []string{"20240101120000-abcdef123456"}},
+		},
+		{
+			text: "v1.2.4-0.20240101120000-abcdef123456",
+			want: semver{major: 1, minor: 2, patch: 4, prerelease: []string{"0", "20240101120000-abcdef123456"}},
+		},
+		{text: "", wantErr: true},
+		{text: "v1.2.3.4", wantErr: true},
+		{text: "v01.2.3", wantErr: true},
+		{text: "v1.-2.3", wantErr: true},
+		{text: "v1.x", wantErr: true},
+		{text: "v1.2.3-", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			got, err := parseSemver(test.text)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("parseSemver(%q) error = %v, want error %v", test.text, err, test.wantErr)
+			}
+			if !test.wantErr && !reflect.DeepEqual(got, test.want) {
+				t.Errorf("parseSemver(%q) = %+v, want %+v", test.text, got, test.want)
+			}
+		})
+	}
+}
+
+func TestSemverCompare(t *testing.T) {
+	// Each version sorts before the next one
+	ordered := []string{
+		"v0.0.0-20230101000000-abcdef123456",
+		"v0.0.0-20240101000000-abcdef123456",
+		"v0.9.0",
+		"v1.0.0-0.3.7",
+		"v1.0.0-alpha",
+		"v1.0.0-alpha.1",
+		"v1.0.0-alpha.beta",
+		"v1.0.0-beta",
+		"v1.0.0-beta.2",
+		"v1.0.0-beta.11",
+		"v1.0.0-rc.1",
+		"v1.0.0",
+		"v1.2.4-0.20240101120000-abcdef123456",
+		"v1.2.4",
+		"v1.10.0",
+		"v2.0.0",
+	}
+	for i := range ordered {
+		for j := range ordered {
+			left, err := parseSemver(ordered[i])
+			if err != nil {
+				t.Fatal(err)
+			}
+			right, err := parseSemver(ordered[j])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := left.compare(right), compareInts(i, j); got != want {
+				t.Errorf("%s.compare(%s) = %d, want %d", ordered[i], ordered[j], got, want)
+			}
+		}
+	}
+
+	equal := [][2]string{{"v1", "v1.0.0"}, {"1.2", "v1.2.0"}, {"v1.0.0+build.1", "v1.0.0+build.2"}}
+	for _, pair := range equal {
+		left, _ := parseSemver(pair[0])
+		right, _ := parseSemver(pair[1])
+		if got := left.compare(right); got != 0 {
+			t.Errorf("%s.compare(%s) = %d, want 0", pair[0], pair[1], got)
+		}
+	}
+}
+
+func TestParseVersionRange(t *testing.T) {
+	tests := []struct {
+		text     string
+		wantErr  bool
+		contains []string
+		excludes []string
+	}{
+		{
+			text:     "v1.4.2",
+			contains: []string{"v1.4.2", "1.4.2"},
+			excludes: []string{"v1.4.1", "v1.4.3", "v1.4.2-rc.1"},
+		},
+		{
+			text:     ">=1.0.0, <1.4.2",
+			contains: []string{"v1.0.0", "v1.4.1", "v1.4.2-rc.1"},
+			excludes: []string{"v0.9.9", "v1.0.0-rc.1", "v1.4.2", "v2.0.0"},
+		},
+		{
+			text:     ">=1.0.0 <1.4.2",
+			contains: []string{"v1.2.0"},
+			excludes: []string{"v1.4.2"},
+		},
+		{
+			text:     "<1.0.0 || >=2.0.0-rc.1",
+			contains: []string{"v0.1.0", "v0.0.0-20240101120000-abcdef123456", "v2.0.0-rc.1", "v3.0.0"},
+			excludes: []string{"v1.0.0", "v1.9.9", "v2.0.0-beta"},
+		},
+		{
+			text:     ">v1.2 , !=v1.3.0 , <=v1.5",
+			contains: []string{"v1.2.1", "v1.5.0"},
+			excludes: []string{"v1.2.0", "v1.3.0", "v1.5.1"},
+		},
+		{
+			text:     "=2.0.0 || 3.0.0",
+			contains: []string{"v2.0.0", "v3.0.0"},
+			excludes: []string{"v2.5.0"},
+		},
+		{text: "", wantErr: true},
+		{text: ">=1.0.0 ||", wantErr: true},
+		{text: ">=1.0.0, <one", wantErr: true},
+		{text: "~1.2.0", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			ranges, err := parseVersionRange(test.text)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("parseVersionRange(%q) error = %v, want error %v", test.text, err, test.wantErr)
+			}
+			for _, text := range test.contains {
+				version, err := parseSemver(text)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !ranges.Contains(version) {
+					t.Errorf("%q does not contain %s", test.text, text)
+				}
+			}
+			for _, text := range test.excludes {
+				version, err := parseSemver(text)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if ranges.Contains(version) {
+					t.Errorf("%q contains %s", test.text, text)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package fix
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines shown around each change.
+const contextLines = 3
+
+// diffOp is one line of an edit script: ' ' keeps, '-' deletes and '+'
+// inserts a line.
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// writeUnified writes the unified diff turning before into after.
+func writeUnified(w io.Writer, fromName, toName string, before, after []byte) error {
+	ops := diffLines(splitLines(string(before)), splitLines(string(after)))
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "--- %s\n+++ %s\n", fromName, toName)
+
+	// Positions of the first line of each op in both files
+	fromLines := make([]int, len(ops)+1)
+	toLines := make([]int, len(ops)+1)
+	for idx, op := range ops {
+		fromLines[idx+1], toLines[idx+1] = fromLines[idx], toLines[idx]
+		if op.kind != '+' {
+			fromLines[idx+1]++
+		}
+		if op.kind != '-' {
+			toLines[idx+1]++
+		}
+	}
+
+	for idx := 0; idx < len(ops); {
+		if ops[idx].kind == ' ' {
+			idx++
+			continue
+		}
+		// Grow the hunk while the next change is close enough to share context
+		start := max(idx-contextLines, 0)
+		end := idx
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			next := end
+			for next < len(ops) && ops[next].kind == ' ' {
+				next++
+			}
+			if next == len(ops) || next-end > 2*contextLines {
+				end = min(end+contextLines, len(ops))
+				break
+			}
+			end = next
+		}
+
+		fromCount := fromLines[end] - fromLines[start]
+		toCount := toLines[end] - toLines[start]
+		fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(fromLines[start], fromCount), hunkRange(toLines[start], toCount))
+		for _, op := range ops[start:end] {
+			out.WriteByte(op.kind)
+			out.WriteString(op.line)
+			if !strings.HasSuffix(op.line, "\n") {
+				out.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		idx = end
+	}
+	return out.Flush()
+}
+
+// hunkRange formats the start and length of a hunk side, counting lines from one.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// splitLines splits text after each newline, keeping the newlines.
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines computes a shortest edit script with Myers' algorithm. The
+// common prefix and suffix are matched first, so the cost only depends on
+// the size of the changed region.
+func diffLines(before, after []string) []diffOp {
+	prefix := 0
+	for prefix < len(before) && prefix < len(after) && before[prefix] == after[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(before)-prefix && suffix < len(after)-prefix &&
+		before[len(before)-1-suffix] == after[len(after)-1-suffix] {
+		suffix++
+	}
+
+	var ops []diffOp
+	for _, line := range before[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	ops = append(ops, myers(before[prefix:len(before)-suffix], after[prefix:len(after)-suffix])...)
+	for _, line := range before[len(before)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
+
+func myers(before, after []string) []diffOp {
+	n, m := len(before), len(after)
+	offset := n + m + 1
+	frontier := make([]int, 2*offset+1)
+	var trace [][]int
+
+search:
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, append([]int(nil), frontier...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && frontier[offset+k-1] < frontier[offset+k+1]) {
+				x = frontier[offset+k+1]
+			} else {
+				x = frontier[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && before[x] == after[y] {
+				x++
+				y++
+			}
+			frontier[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// Walk the trace back from the end, collecting ops in reverse
+	var reversed []diffOp
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		previous := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && previous[offset+k-1] < previous[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := previous[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			reversed = append(reversed, diffOp{' ', before[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				reversed = append(reversed, diffOp{'+', after[y-1]})
+			} else {
+				reversed = append(reversed, diffOp{'-', before[x-1]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	ops := make([]diffOp, len(reversed))
+	for idx, op := range reversed {
+		ops[len(reversed)-1-idx] = op
+	}
+	return ops
+}
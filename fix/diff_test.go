@@ -0,0 +1,149 @@
+package fix
+
+import (
+	"strings"
+	"testing"
+)
+
+// lettered returns n lines "a\n", "b\n"..., with some lines replaced.
+func lettered(n int, replace map[int]string) string {
+	var text strings.Builder
+	for line := 1; line <= n; line++ {
+		if replacement, ok := replace[line]; ok {
+			text.WriteString(replacement)
+			continue
+		}
+		text.WriteString(string(rune('a'+line-1)) + "\n")
+	}
+	return text.String()
+}
+
+func TestWriteUnified(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   string
+	}{
+		{
+			name:   "identical",
+			before: "a\nb\n",
+			after:  "a\nb\n",
+			want:   "",
+		},
+		{
+			name:   "change in the middle",
+			before: lettered(10, nil),
+			after:  lettered(10, map[int]string{5: "changed\n"}),
+			want: "@@ -2,7 +2,7 @@\n" +
+				" b\n c\n d\n-e\n+changed\n f\n g\n h\n",
+		},
+		{
+			name:   "hunk header at the start of the file",
+			before: "a\nb\nc\nd\ne\n",
+			after:  "a\nB\nc\nd\ne\n",
+			want:   "@@ -1,5 +1,5 @@\n a\n-b\n+B\n c\n d\n e\n",
+		},
+		{
+			name:   "insertion counts one line on the new side",
+			before: "a\n",
+			after:  "a\nb\n",
+			want:   "@@ -1 +1,2 @@\n a\n+b\n",
+		},
+		{
+			name:   "new content",
+			before: "",
+			after:  "a\nb\n",
+			want:   "@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name:   "removed content",
+			before: "a\nb\n",
+			after:  "",
+			want:   "@@ -1,2 +0,0 @@\n-a\n-b\n",
+		},
+		{
+			name:   "no trailing newline on either side",
+			before: "a\nb",
+			after:  "a\nc",
+			want: "@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n" +
+				"+c\n\\ No newline at end of file\n",
+		},
+		{
+			name:   "trailing newline added",
+			before: "a\nb",
+			after:  "a\nb\n",
+			want:   "@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+		{
+			name:   "changes six lines apart share a hunk",
+			before: lettered(10, nil),
+			after:  lettered(10, map[int]string{2: "two\n", 9: "nine\n"}),
+			want: "@@ -1,10 +1,10 @@\n" +
+				" a\n-b\n+two\n c\n d\n e\n f\n g\n h\n-i\n+nine\n j\n",
+		},
+		{
+			name:   "changes seven lines apart get their own hunks",
+			before: lettered(12, nil),
+			after:  lettered(12, map[int]string{2: "two\n", 10: "ten\n"}),
+			want: "@@ -1,5 +1,5 @@\n a\n-b\n+two\n c\n d\n e\n" +
+				"@@ -7,6 +7,6 @@\n g\n h\n i\n-j\n+ten\n k\n l\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out strings.Builder
+			if err := writeUnified(&out, "a/f.go", "b/f.go", []byte(test.before), []byte(test.after)); err != nil {
+				t.Fatal(err)
+			}
+			want := "--- a/f.go\n+++ b/f.go\n" + test.want
+			if out.String() != want {
+				t.Errorf("writeUnified() =\n%s\nwant\n%s", out.String(), want)
+			}
+		})
+	}
+}
+
+func TestMyers(t *testing.T) {
+	tests := []struct {
+		before, after string
+		// changes is the length of the shortest edit script
+		changes int
+	}{
+		{"", "", 0},
+		{"abc", "abc", 0},
+		{"", "abc", 3},
+		{"abc", "", 3},
+		{"abcabba", "cbabac", 5},
+		{"abcdef", "abxdef", 2},
+		{"aaaa", "aa", 2},
+		{"abab", "baba", 2},
+	}
+	for _, test := range tests {
+		t.Run(test.before+"->"+test.after, func(t *testing.T) {
+			before, after := strings.Split(test.before, ""), strings.Split(test.after, "")
+			ops := myers(before, after)
+
+			var gotBefore, gotAfter []string
+			changes := 0
+			for _, op := range ops {
+				if op.kind != '+' {
+					gotBefore = append(gotBefore, op.line)
+				}
+				if op.kind != '-' {
+					gotAfter = append(gotAfter, op.line)
+				}
+				if op.kind != ' ' {
+					changes++
+				}
+			}
+			if strings.Join(gotBefore, "") != test.before || strings.Join(gotAfter, "") != test.after {
+				t.Errorf("myers() script turns %q into %q, want %q into %q",
+					strings.Join(gotBefore, ""), strings.Join(gotAfter, ""), test.before, test.after)
+			}
+			if changes != test.changes {
+				t.Errorf("myers() made %d changes, want %d", changes, test.changes)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+// Package fix applies the edits attached to findings to the source files.
+package fix
+
+import (
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/Aadi-IRON/agni/detectors"
+)
+
+// Plan holds the fixed content of every file touched by the findings' edits.
+// Nothing is written until Write is called.
+type Plan struct {
+	Root  string
+	files []fixedFile
+	// Fixed is the number of findings whose edits are part of the plan.
+	Fixed int
+}
+
+type fixedFile struct {
+	path   string
+	before []byte
+	after  []byte
+	mode   os.FileMode
+}
+
+// plannedEdit is an edit together with the finding it fixes.
+type plannedEdit struct {
+	detectors.TextEdit
+	finding *detectors.Finding
+}
+
+// New collects the edits of every finding in results and applies them in
+// memory. Each edited file is formatted with gofmt. New fails, and nothing
+// may be written, when edits of different findings overlap, when an edit
+// does not fit its file or when an edited file no longer parses.
+func New(root string, results []detectors.Result) (*Plan, error) {
+	plan := &Plan{Root: root}
+	byFile := make(map[string][]plannedEdit)
+	for resultIdx := range results {
+		findings := results[resultIdx].Findings
+		for idx := range findings {
+			if len(findings[idx].Edits) == 0 {
+				continue
+			}
+			plan.Fixed++
+			for _, edit := range findings[idx].Edits {
+				byFile[edit.File] = append(byFile[edit.File], plannedEdit{TextEdit: edit, finding: &findings[idx]})
+			}
+		}
+	}
+
+	paths := make([]string, 0, len(byFile))
+	for path := range byFile {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		file, err := plan.fixFile(path, byFile[path])
+		if err != nil {
+			return nil, err
+		}
+		if string(file.after) != string(file.before) {
+			plan.files = append(plan.files, file)
+		}
+	}
+	return plan, nil
+}
+
+// fixFile applies the edits of one file and formats the result.
+func (plan *Plan) fixFile(path string, edits []plannedEdit) (fixedFile, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fixedFile{}, fmt.Errorf("failed to read %s: %v", plan.relative(path), err)
+	}
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return fixedFile{}, fmt.Errorf("failed to read %s: %v", plan.relative(path), err)
+	}
+
+	sort.SliceStable(edits, func(i, j int) bool {
+		if edits[i].Start != edits[j].Start {
+			return edits[i].Start < edits[j].Start
+		}
+		return edits[i].End < edits[j].End
+	})
+	// Several findings may ask for the same edit, such as two findings on the
+	// same variable; keep one copy of each
+	var unique []plannedEdit
+	for _, edit := range edits {
+		if len(unique) > 0 && unique[len(unique)-1].TextEdit == edit.TextEdit {
+			continue
+		}
+		if edit.Start < 0 || edit.End < edit.Start || edit.End > len(src) {
+			return fixedFile{}, fmt.Errorf("fix for %s does not fit %s; was the file changed since the analysis?",
+				describe(edit.finding), plan.relative(path))
+		}
+		if len(unique) > 0 {
+			previous := unique[len(unique)-1]
+			if previous.End > edit.Start || (previous.Start == edit.Start && previous.End == edit.End) {
+				return fixedFile{}, fmt.Errorf("conflicting fixes in %s: %s and %s; fix one rule at a time with -only",
+					plan.relative(path), describe(previous.finding), describe(edit.finding))
+			}
+		}
+		unique = append(unique, edit)
+	}
+
+	var fixed []byte
+	last := 0
+	for _, edit := range unique {
+		fixed = append(fixed, src[last:edit.Start]...)
+		fixed = append(fixed, edit.NewText...)
+		last = edit.End
+	}
+	fixed = append(fixed, src[last:]...)
+	formatted, err := format.Source(fixed)
+	if err != nil {
+		return fixedFile{}, fmt.Errorf("fixes would leave %s invalid: %v", plan.relative(path), err)
+	}
+	return fixedFile{path: path, before: src, after: formatted, mode: info.Mode().Perm()}, nil
+}
+
+// Files returns the number of files the plan changes.
+func (plan *Plan) Files() int {
+	return len(plan.files)
+}
+
+// Write saves every fixed file.
+func (plan *Plan) Write() error {
+	for _, file := range plan.files {
+		if err := os.WriteFile(file.path, file.after, file.mode); err != nil {
+			return fmt.Errorf("failed to write %s: %v", plan.relative(file.path), err)
+		}
+	}
+	return nil
+}
+
+// Diff writes the changes of the plan as a unified diff, with paths relative
+// to the root, that "git apply" and "patch -p1" accept.
+func (plan *Plan) Diff(w io.Writer) error {
+	for _, file := range plan.files {
+		name := filepath.ToSlash(plan.relative(file.path))
+		if err := writeUnified(w, "a/"+name, "b/"+name, file.before, file.after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Filter removes the findings that the plan fixes from results.
+func (plan *Plan) Filter(results []detectors.Result) {
+	for idx := range results {
+		kept := results[idx].Findings[:0]
+		for _, finding := range results[idx].Findings {
+			if len(finding.Edits) == 0 {
+				kept = append(kept, finding)
+			}
+		}
+		results[idx].Findings = kept
+	}
+}
+
+func (plan *Plan) relative(path string) string {
+	if rel, err := filepath.Rel(plan.Root, path); err == nil {
+		return rel
+	}
+	return path
+}
+
+// describe names a finding in error messages.
+func describe(finding *detectors.Finding) string {
+	return fmt.Sprintf("%s at line %d:%d", finding.RuleID, finding.Line, finding.Column)
+}
@@ -0,0 +1,143 @@
+package fix
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Aadi-IRON/agni/detectors"
+)
+
+const source = `package p
+
+func F(Count int) int {
+	return Count
+}
+`
+
+func TestNew(t *testing.T) {
+	// edit replaces the given occurrence, counting from one, of old in source
+	edit := func(path, old, newText string, occurrence int) detectors.TextEdit {
+		start := -1
+		for range occurrence {
+			start += 1 + strings.Index(source[start+1:], old)
+		}
+		return detectors.TextEdit{File: path, Start: start, End: start + len(old), NewText: newText}
+	}
+	finding := func(rule string, edits ...detectors.TextEdit) detectors.Finding {
+		return detectors.Finding{RuleID: rule, Line: 3, Column: 8, Edits: edits}
+	}
+
+	tests := []struct {
+		name     string
+		findings func(path string) []detectors.Finding
+		want     string
+		fixed    int
+		err      string
+	}{
+		{
+			name: "edits of one finding",
+			findings: func(path string) []detectors.Finding {
+				return []detectors.Finding{finding("capital-vars", edit(path, "Count", "count", 1), edit(path, "Count", "count", 2))}
+			},
+			want:  strings.ReplaceAll(source, "Count", "count"),
+			fixed: 1,
+		},
+		{
+			name: "findings without edits",
+			findings: func(path string) []detectors.Finding {
+				return []detectors.Finding{finding("capital-vars")}
+			},
+			want:  source,
+			fixed: 0,
+		},
+		{
+			name: "identical edits of two findings are applied once",
+			findings: func(path string) []detectors.Finding {
+				return []detectors.Finding{
+					finding("capital-vars", edit(path, "Count", "count", 1), edit(path, "Count", "count", 2)),
+					finding("capital-vars", edit(path, "Count", "count", 1)),
+				}
+			},
+			want:  strings.ReplaceAll(source, "Count", "count"),
+			fixed: 2,
+		},
+		{
+			name: "result is formatted",
+			findings: func(path string) []detectors.Finding {
+				return []detectors.Finding{finding("capital-vars", edit(path, "int {", "int    {", 1))}
+			},
+			want:  source,
+			fixed: 1,
+		},
+		{
+			name: "different edits of the same range conflict",
+			findings: func(path string) []detectors.Finding {
+				return []detectors.Finding{
+					finding("capital-vars", edit(path, "Count", "count", 1)),
+					finding("unused-params", edit(path, "Count", "_", 1)),
+				}
+			},
+			err: "conflicting fixes in f.go: capital-vars at line 3:8 and unused-params at line 3:8",
+		},
+		{
+			name: "overlapping edits conflict",
+			findings: func(path string) []detectors.Finding {
+				return []detectors.Finding{
+					finding("capital-vars", edit(path, "Count", "count", 1)),
+					finding("unused-params", edit(path, "Count int", "", 1)),
+				}
+			},
+			err: "conflicting fixes in f.go",
+		},
+		{
+			name: "edit beyond the end of the file",
+			findings: func(path string) []detectors.Finding {
+				return []detectors.Finding{finding("capital-vars", detectors.TextEdit{File: path, Start: len(source), End: len(source) + 1})}
+			},
+			err: "does not fit f.go",
+		},
+		{
+			name: "edit leaving the file invalid",
+			findings: func(path string) []detectors.Finding {
+				return []detectors.Finding{finding("capital-vars", edit(path, "}", "", 1))}
+			},
+			err: "fixes would leave f.go invalid",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := t.TempDir()
+			path := filepath.Join(root, "f.go")
+			if err := os.WriteFile(path, []byte(source), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			results := []detectors.Result{{Findings: test.findings(path)}}
+
+			plan, err := New(root, results)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("New() error = %v, want %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if plan.Fixed != test.fixed {
+				t.Errorf("Fixed = %d, want %d", plan.Fixed, test.fixed)
+			}
+			if err := plan.Write(); err != nil {
+				t.Fatal(err)
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf("fixed file =\n%s\nwant\n%s", got, test.want)
+			}
+		})
+	}
+}
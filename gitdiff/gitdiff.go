@@ -0,0 +1,249 @@
+// Package gitdiff limits findings to the lines touched by a change.
+package gitdiff
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Aadi-IRON/agni/detectors"
+)
+
+// hunkHeader matches the header of a unified diff hunk, capturing the start
+// line and optional line count of the old and new versions.
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// FileChanges describes the changed lines of a single file.
+type FileChanges struct {
+	// Whole is set for files that are new in their entirety.
+	Whole bool
+	// Lines holds the changed line numbers of the new version.
+	Lines map[int]bool
+}
+
+// Changes describes a diff. Files are keyed by their path relative to Root,
+// using forward slashes.
+type Changes struct {
+	Root  string
+	Files map[string]*FileChanges
+}
+
+// Parse reads a unified diff, such as the output of "git diff" or a patch
+// file. Paths in the diff are interpreted relative to root. Lines are read
+// as hunk content while the counts of the current hunk header last, so an
+// added line starting with "++ " is not mistaken for a file header.
+func Parse(r io.Reader, root string) (*Changes, error) {
+	changes := &Changes{Root: root, Files: make(map[string]*FileChanges)}
+	var current *FileChanges
+	// Lines of the current hunk still to be read in each version
+	oldRemaining, newRemaining := 0, 0
+	newLine := 0
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if oldRemaining > 0 || newRemaining > 0 {
+			switch {
+			case strings.HasPrefix(line, "+"):
+				if current != nil {
+					current.Lines[newLine] = true
+				}
+				newLine++
+				newRemaining--
+			case strings.HasPrefix(line, "-"):
+				oldRemaining--
+			case strings.HasPrefix(line, " "), line == "":
+				// Some tools strip the space of empty context lines
+				newLine++
+				oldRemaining--
+				newRemaining--
+			case strings.HasPrefix(line, `\`):
+				// "\ No newline at end of file"
+			default:
+				return nil, fmt.Errorf("hunk ends early at %q", line)
+			}
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			name, err := fileName(strings.TrimPrefix(line, "+++ "))
+			if err != nil {
+				return nil, err
+			}
+			if name == "/dev/null" {
+				current = nil
+				continue
+			}
+			name = strings.TrimPrefix(name, "b/")
+			current = changes.file(name)
+		case strings.HasPrefix(line, "@@"):
+			match := hunkHeader.FindStringSubmatch(line)
+			if match == nil {
+				return nil, fmt.Errorf("malformed hunk header %q", line)
+			}
+			oldRemaining, newRemaining = hunkCount(match[1]), hunkCount(match[3])
+			newLine, _ = strconv.Atoi(match[2])
+		}
+		// Other lines, such as "--- " and "diff --git", are not needed
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading diff: %v", err)
+	}
+	if oldRemaining > 0 || newRemaining > 0 {
+		return nil, fmt.Errorf("diff ends in the middle of a hunk")
+	}
+	return changes, nil
+}
+
+// hunkCount returns the line count of a hunk header, which is one when left out.
+func hunkCount(count string) int {
+	if count == "" {
+		return 1
+	}
+	number, _ := strconv.Atoi(count)
+	return number
+}
+
+// fileName returns the path of a file header. Git quotes paths with unusual
+// characters as Go-style string literals, and diff -u follows them with a
+// tab and a timestamp.
+func fileName(header string) (string, error) {
+	name, _, _ := strings.Cut(header, "\t")
+	if !strings.HasPrefix(name, `"`) {
+		return name, nil
+	}
+	unquoted, err := strconv.Unquote(name)
+	if err != nil {
+		return "", fmt.Errorf("malformed file name %s", name)
+	}
+	return unquoted, nil
+}
+
+// ParseFile reads a patch file. Its paths are interpreted relative to the git
+// top-level directory of dir, or to dir itself outside a repository.
+func ParseFile(patchFile, dir string) (*Changes, error) {
+	file, err := os.Open(patchFile)
+	if err != nil {
+		return nil, fmt.Errorf("error opening diff: %v", err)
+	}
+	defer file.Close()
+	root, err := gitOutput(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		root = dir
+	}
+	return Parse(file, strings.TrimSpace(root))
+}
+
+// FromRevision collects the changes between rev and the working tree of the
+// repository containing dir, including untracked files, using the local git
+// binary.
+func FromRevision(dir, rev string) (*Changes, error) {
+	root, err := gitOutput(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	root = strings.TrimSpace(root)
+	diff, err := gitOutput(root, "diff", "--no-color", "--no-ext-diff", "-U0", rev, "--")
+	if err != nil {
+		return nil, err
+	}
+	changes, err := Parse(strings.NewReader(diff), root)
+	if err != nil {
+		return nil, err
+	}
+	// -z lists the names unquoted
+	untracked, err := gitOutput(root, "ls-files", "-z", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range strings.Split(untracked, "\x00") {
+		if name != "" {
+			changes.file(name).Whole = true
+		}
+	}
+	return changes, nil
+}
+
+// Filter removes findings outside the changed lines from results and returns
+// how many were removed. Findings without a line are kept when their file
+// changed.
+func (changes *Changes) Filter(results []detectors.Result) int {
+	root := resolve(changes.Root)
+	removed := 0
+	for idx := range results {
+		kept := results[idx].Findings[:0]
+		for _, finding := range results[idx].Findings {
+			if changes.contains(root, finding) {
+				kept = append(kept, finding)
+			} else {
+				removed++
+			}
+		}
+		results[idx].Findings = kept
+	}
+	return removed
+}
+
+// contains reports whether a finding lies on a changed line.
+func (changes *Changes) contains(root string, finding detectors.Finding) bool {
+	relative, err := filepath.Rel(root, resolve(finding.File))
+	if err != nil {
+		return false
+	}
+	file, ok := changes.Files[filepath.ToSlash(relative)]
+	if !ok {
+		return false
+	}
+	if file.Whole || finding.Line == 0 {
+		return true
+	}
+	last := finding.EndLine
+	if last < finding.Line {
+		last = finding.Line
+	}
+	for line := finding.Line; line <= last; line++ {
+		if file.Lines[line] {
+			return true
+		}
+	}
+	return false
+}
+
+// file returns the changes of the named file, creating them when needed.
+func (changes *Changes) file(name string) *FileChanges {
+	name = filepath.ToSlash(filepath.Clean(name))
+	file, ok := changes.Files[name]
+	if !ok {
+		file = &FileChanges{Lines: make(map[int]bool)}
+		changes.Files[name] = file
+	}
+	return file
+}
+
+// resolve evaluates symlinks so that paths reported by git and paths given on
+// the command line compare equal.
+func resolve(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return path
+}
+
+// gitOutput runs git in dir and returns its standard output.
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return string(output), nil
+}
@@ -0,0 +1,202 @@
+package gitdiff
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/Aadi-IRON/agni/detectors"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		diff string
+		// want maps each file to its changed lines
+		want    map[string][]int
+		wantErr string
+	}{
+		{
+			name: "git diff",
+			diff: `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -3,6 +3,7 @@ import "fmt"
+ func main() {
+ 	fmt.Println("a")
+-	fmt.Println("b")
++	fmt.Println("B")
++	fmt.Println("c")
+ 	fmt.Println("d")
+
+ }
+@@ -20 +21 @@ func helper() {
+-	return 1
++	return 2
+`,
+			want: map[string][]int{"main.go": {5, 6, 21}},
+		},
+		{
+			name: "zero context",
+			diff: `--- a/a.go
++++ b/a.go
+@@ -10,2 +9,0 @@
+-x
+-y
+@@ -15,0 +14,2 @@
++z
++w
+`,
+			want: map[string][]int{"a.go": {14, 15}},
+		},
+		{
+			name: "added lines that look like file headers",
+			diff: `--- a/notes.go
++++ b/notes.go
+@@ -1,2 +1,4 @@
+ package notes
++++ b/other.go
++--- a/other.go
+ // end
+--- a/next.go
++++ b/next.go
+@@ -1 +1 @@
+-package next
++package next2
+`,
+			want: map[string][]int{"notes.go": {2, 3}, "next.go": {1}},
+		},
+		{
+			name: "added line that looks like a hunk header",
+			diff: `--- a/a.go
++++ b/a.go
+@@ -1,0 +2,2 @@
++@@ -1 +1 @@
++x
+`,
+			want: map[string][]int{"a.go": {2, 3}},
+		},
+		{
+			name: "no newline at end of file",
+			diff: `--- a/a.go
++++ b/a.go
+@@ -1,2 +1,2 @@
+ package a
+-var x = 1
+\ No newline at end of file
++var x = 2
+\ No newline at end of file
+`,
+			want: map[string][]int{"a.go": {2}},
+		},
+		{
+			name: "deleted file",
+			diff: `--- a/gone.go
++++ /dev/null
+@@ -1,2 +0,0 @@
+-package gone
+-
+`,
+			want: map[string][]int{},
+		},
+		{
+			name: "quoted path",
+			diff: `--- "a/caf\303\251 menu.go"
++++ "b/caf\303\251 menu.go"
+@@ -1 +1 @@
+-package a
++package b
+`,
+			want: map[string][]int{"café menu.go": {1}},
+		},
+		{
+			name: "diff -u timestamps",
+			diff: "--- a.go\t2024-01-01 10:00:00.000000000 +0100\n" +
+				"+++ a.go\t2024-01-02 10:00:00.000000000 +0100\n" +
+				"@@ -1 +1,2 @@\n package a\n+var x int\n",
+			want: map[string][]int{"a.go": {2}},
+		},
+		{
+			name:    "malformed hunk header",
+			diff:    "--- a/a.go\n+++ b/a.go\n@@ -1 +x @@\n",
+			wantErr: "malformed hunk header",
+		},
+		{
+			name:    "malformed quoted path",
+			diff:    "--- \"a/a.go\n+++ \"b/a.go\n",
+			wantErr: "malformed file name",
+		},
+		{
+			name:    "truncated hunk",
+			diff:    "--- a/a.go\n+++ b/a.go\n@@ -1,3 +1,3 @@\n package a\n",
+			wantErr: "middle of a hunk",
+		},
+		{
+			name:    "hunk ending early",
+			diff:    "--- a/a.go\n+++ b/a.go\n@@ -1,3 +1,3 @@\n package a\ndiff --git a/b.go b/b.go\n",
+			wantErr: "hunk ends early",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			changes, err := Parse(strings.NewReader(test.diff), "/repo")
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("Parse() error = %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			got := make(map[string][]int)
+			for name, file := range changes.Files {
+				lines := []int{}
+				for line := range file.Lines {
+					lines = append(lines, line)
+				}
+				sort.Ints(lines)
+				got[name] = lines
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Parse() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	root := t.TempDir()
+	changes := &Changes{Root: root, Files: map[string]*FileChanges{
+		"a.go":     {Lines: map[int]bool{5: true}},
+		"new/b.go": {Whole: true, Lines: map[int]bool{}},
+	}}
+	finding := func(name string, line, endLine int) detectors.Finding {
+		return detectors.Finding{File: filepath.Join(root, name), Line: line, EndLine: endLine}
+	}
+	results := []detectors.Result{{Findings: []detectors.Finding{
+		finding("a.go", 5, 0),
+		finding("a.go", 3, 6),
+		finding("a.go", 0, 0),
+		finding("a.go", 4, 0),
+		finding("new/b.go", 40, 0),
+		finding("c.go", 5, 0),
+	}}}
+
+	if removed := changes.Filter(results); removed != 2 {
+		t.Errorf("Filter() removed %d findings, want 2", removed)
+	}
+	var kept []string
+	for _, finding := range results[0].Findings {
+		relative, _ := filepath.Rel(root, finding.File)
+		kept = append(kept, fmt.Sprintf("%s:%d", filepath.ToSlash(relative), finding.Line))
+	}
+	want := []string{"a.go:5", "a.go:3", "a.go:0", "new/b.go:40"}
+	if !reflect.DeepEqual(kept, want) {
+		t.Errorf("Filter() kept %v, want %v", kept, want)
+	}
+}
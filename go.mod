@@ -1,3 +1,8 @@
 module github.com/Aadi-IRON/agni
 
 go 1.26.0
+
+require (
+	github.com/BurntSushi/toml v1.6.0
+	gopkg.in/yaml.v3 v3.0.1
+)
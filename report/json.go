@@ -0,0 +1,70 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/Aadi-IRON/agni/detectors"
+)
+
+// JSON renders a run as a single machine-readable JSON document.
+type JSON struct{}
+
+// jsonDocument is the top-level object written by the JSON reporter.
+type jsonDocument struct {
+	Tool       string              `json:"tool"`
+	Version    string              `json:"version,omitempty"`
+	Root       string              `json:"root"`
+	StartedAt  time.Time           `json:"startedAt"`
+	DurationMs int64               `json:"durationMs"`
+	Total      int                 `json:"total"`
+	Baselined  int                 `json:"baselined,omitempty"`
+	Summaries  []jsonSummary       `json:"summaries"`
+	Findings   []detectors.Finding `json:"findings"`
+}
+
+// jsonSummary describes the outcome of a single detector.
+type jsonSummary struct {
+	Detector    string             `json:"detector"`
+	Description string             `json:"description"`
+	Category    detectors.Category `json:"category"`
+	Severity    detectors.Severity `json:"severity"`
+	Findings    int                `json:"findings"`
+	DurationMs  int64              `json:"durationMs"`
+	Error       string             `json:"error,omitempty"`
+}
+
+// Report implements Reporter.
+func (JSON) Report(w io.Writer, run *Run) error {
+	document := jsonDocument{
+		Tool:       "agni",
+		Version:    run.Version,
+		Root:       run.Root,
+		StartedAt:  run.Start,
+		DurationMs: run.Duration.Milliseconds(),
+		Baselined:  run.Baselined,
+		Summaries:  []jsonSummary{},
+		Findings:   []detectors.Finding{},
+	}
+	for _, result := range run.Results {
+		summary := jsonSummary{
+			Detector:    result.Detector.Name(),
+			Description: result.Detector.Description(),
+			Category:    result.Detector.Category(),
+			Severity:    result.Detector.Severity(),
+			Findings:    len(result.Findings),
+			DurationMs:  result.Duration.Milliseconds(),
+		}
+		if result.Err != nil {
+			summary.Error = result.Err.Error()
+		}
+		document.Summaries = append(document.Summaries, summary)
+		document.Findings = append(document.Findings, result.Findings...)
+		document.Total += len(result.Findings)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}
@@ -0,0 +1,45 @@
+// Package report renders the results of Agni detectors.
+package report
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Aadi-IRON/agni/detectors"
+)
+
+// Run is the complete outcome of one Agni invocation.
+type Run struct {
+	// Root is the absolute path of the scanned directory.
+	Root string
+	// Version is the agni version that produced the run.
+	Version  string
+	Start    time.Time
+	Duration time.Duration
+	Results  []detectors.Result
+	// Baselined is the number of findings hidden because they are recorded
+	// in a baseline file.
+	Baselined int
+}
+
+// Reporter writes the outcome of a run to an output stream.
+type Reporter interface {
+	Report(w io.Writer, run *Run) error
+}
+
+// Formats lists the names accepted by New.
+var Formats = []string{"text", "json", "sarif"}
+
+// New returns the reporter for the given output format.
+func New(format string) (Reporter, error) {
+	switch format {
+	case "", "text":
+		return Text{}, nil
+	case "json":
+		return JSON{}, nil
+	case "sarif":
+		return SARIF{}, nil
+	}
+	return nil, fmt.Errorf("unknown output format %q (expected one of %v)", format, Formats)
+}
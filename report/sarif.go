@@ -0,0 +1,218 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/Aadi-IRON/agni/detectors"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	// sarifRootID is the uriBaseId that finding locations are relative to.
+	sarifRootID = "SRCROOT"
+)
+
+// SARIF renders a run as a SARIF 2.1.0 log for code-scanning integrations.
+type SARIF struct{}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                   `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifactLoc `json:"originalUriBaseIds,omitempty"`
+	Invocations        []sarifInvocation           `json:"invocations"`
+	Results            []sarifResult               `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+	Properties           map[string]any     `json:"properties,omitempty"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+type sarifNotification struct {
+	Level      string              `json:"level"`
+	Message    sarifMessage        `json:"message"`
+	Descriptor *sarifDescriptorRef `json:"associatedRule,omitempty"`
+}
+
+type sarifDescriptorRef struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID     string          `json:"ruleId"`
+	RuleIndex  int             `json:"ruleIndex"`
+	Level      string          `json:"level"`
+	Message    sarifMessage    `json:"message"`
+	Locations  []sarifLocation `json:"locations"`
+	Properties map[string]any  `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLoc `json:"artifactLocation"`
+	Region           *sarifRegion     `json:"region,omitempty"`
+}
+
+type sarifArtifactLoc struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+// Report implements Reporter.
+func (SARIF) Report(w io.Writer, run *Run) error {
+	output := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "agni",
+			Version:        run.Version,
+			InformationURI: "https://github.com/Aadi-IRON/agni",
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+	if run.Root != "" {
+		output.OriginalURIBaseIDs = map[string]sarifArtifactLoc{
+			sarifRootID: {URI: fileURI(run.Root) + "/"},
+		}
+	}
+
+	invocation := sarifInvocation{ExecutionSuccessful: true}
+	ruleIndex := make(map[string]int)
+	for _, result := range run.Results {
+		detector := result.Detector
+		ruleIndex[detector.Name()] = len(output.Tool.Driver.Rules)
+		output.Tool.Driver.Rules = append(output.Tool.Driver.Rules, sarifRule{
+			ID:                   detector.Name(),
+			Name:                 detector.Name(),
+			ShortDescription:     sarifMessage{Text: detector.Description()},
+			DefaultConfiguration: sarifConfiguration{Level: sarifLevel(detector.Severity())},
+			Properties:           map[string]any{"category": detector.Category()},
+		})
+		if result.Err != nil {
+			invocation.ExecutionSuccessful = false
+			invocation.ToolExecutionNotifications = append(invocation.ToolExecutionNotifications, sarifNotification{
+				Level:      "error",
+				Message:    sarifMessage{Text: result.Err.Error()},
+				Descriptor: &sarifDescriptorRef{ID: detector.Name()},
+			})
+		}
+	}
+
+	for _, result := range run.Results {
+		for _, finding := range result.Findings {
+			index, ok := ruleIndex[finding.RuleID]
+			if !ok {
+				index = ruleIndex[result.Detector.Name()]
+			}
+			entry := sarifResult{
+				RuleID:    finding.RuleID,
+				RuleIndex: index,
+				Level:     sarifLevel(finding.Severity),
+				Message:   sarifMessage{Text: finding.Message},
+				Locations: []sarifLocation{{PhysicalLocation: physicalLocation(run.Root, finding)}},
+			}
+			if finding.SuggestedFix != "" {
+				entry.Properties = map[string]any{"suggestedFix": finding.SuggestedFix}
+			}
+			output.Results = append(output.Results, entry)
+		}
+	}
+	output.Invocations = []sarifInvocation{invocation}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{output},
+	})
+}
+
+// physicalLocation maps a finding's file and position to a SARIF location.
+// Files under root are addressed relative to the SRCROOT base id.
+func physicalLocation(root string, finding detectors.Finding) sarifPhysicalLocation {
+	location := sarifPhysicalLocation{ArtifactLocation: sarifArtifactLoc{URI: fileURI(finding.File)}}
+	if root != "" {
+		if relative, err := filepath.Rel(root, finding.File); err == nil && !strings.HasPrefix(relative, "..") {
+			location.ArtifactLocation = sarifArtifactLoc{
+				URI:       (&url.URL{Path: filepath.ToSlash(relative)}).String(),
+				URIBaseID: sarifRootID,
+			}
+		}
+	}
+	if finding.Line > 0 {
+		location.Region = &sarifRegion{
+			StartLine:   finding.Line,
+			StartColumn: finding.Column,
+			EndLine:     finding.EndLine,
+			EndColumn:   finding.EndColumn,
+		}
+	}
+	return location
+}
+
+// fileURI converts an absolute path into a file:// URI.
+func fileURI(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
+
+// sarifLevel maps a severity onto a SARIF result level.
+func sarifLevel(severity detectors.Severity) string {
+	switch severity {
+	case detectors.SeverityError:
+		return "error"
+	case detectors.SeverityWarning:
+		return "warning"
+	}
+	return "note"
+}
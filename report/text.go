@@ -0,0 +1,101 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Aadi-IRON/agni/config"
+	"github.com/Aadi-IRON/agni/detectors"
+)
+
+// header is the box title and color used for a detector's section.
+type header struct {
+	title string
+	color string
+}
+
+// headers holds the section headers of the built-in detectors.
+var headers = map[string]header{
+	"unused-params":            {"UNUSED PARAMETERS", config.BoldYellow},
+	"unused-constants":         {"UNUSED CONSTANTS", config.BoldGreen},
+	"enum-switch":              {"NON-EXHAUSTIVE ENUM SWITCHES", config.BoldYellow},
+	"duplicate-constants":      {"DUPLICATE CONSTANTS", config.BoldCyan},
+	"unused-messages":          {"UNUSED MESSAGES", config.BoldCyan},
+	"undefined-message-keys":   {"UNDEFINED MESSAGE KEYS", config.BoldPurple},
+	"message-locales":          {"MESSAGE LOCALES", config.BoldCyan},
+	"capital-vars":             {"CAPITAL LETTERS", config.BoldBlue},
+	"deprecated-packages":      {"DEPRECATED PACKAGES", config.BoldRed},
+	"deprecated-internal":      {"DEPRECATED INTERNAL SYMBOLS", config.BoldRed},
+	"exported-but-internal":    {"EXPORTED FUNCTIONS THAT SHOULD BE UNEXPORTED", config.BoldPurple},
+	"dead-code":                {"DEAD CODE", config.BoldPurple},
+	detectors.SuppressionsRule: {"SUPPRESSIONS", config.BoldWhite},
+}
+
+// Text renders results as colored, human-readable console output.
+type Text struct{}
+
+// Report implements Reporter.
+func (Text) Report(w io.Writer, run *Run) error {
+	total := 0
+	for _, result := range run.Results {
+		section := headerFor(result.Detector)
+		fmt.Fprintln(w, config.CreateCompactBoxHeader(section.title, section.color))
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, config.BoldYellow+"🔍 "+result.Detector.Description()+config.Reset)
+		fmt.Fprintln(w)
+
+		for _, finding := range result.Findings {
+			fmt.Fprintf(w, "%s📁 %s%s - %s%s%s\n",
+				config.Red, location(finding), config.Reset,
+				severityColor(finding.Severity), finding.Message, config.Reset)
+			if finding.SuggestedFix != "" {
+				fmt.Fprintf(w, "   %s💡 %s%s\n", config.Green, finding.SuggestedFix, config.Reset)
+			}
+		}
+		if result.Err != nil {
+			fmt.Fprintf(w, "%s❌ %v%s\n", config.Red, result.Err, config.Reset)
+		}
+		if len(result.Findings) == 0 && result.Err == nil {
+			fmt.Fprintln(w, config.BoldGreen+"✅ No issues found."+config.Reset)
+		}
+		fmt.Fprintln(w)
+		total += len(result.Findings)
+	}
+	fmt.Fprintf(w, "%s🔥 %d finding(s) from %d detector(s)%s\n", config.BoldCyan, total, len(run.Results), config.Reset)
+	if run.Baselined > 0 {
+		fmt.Fprintf(w, "%s📌 %d known finding(s) hidden by the baseline%s\n", config.Cyan, run.Baselined, config.Reset)
+	}
+	return nil
+}
+
+// headerFor returns the section header of a detector, deriving one from its
+// name when it is not a built-in detector.
+func headerFor(detector detectors.Detector) header {
+	if section, ok := headers[detector.Name()]; ok {
+		return section
+	}
+	return header{strings.ToUpper(strings.ReplaceAll(detector.Name(), "-", " ")), config.BoldWhite}
+}
+
+// location formats the position of a finding as file:line:column.
+func location(finding detectors.Finding) string {
+	switch {
+	case finding.Line == 0:
+		return finding.File
+	case finding.Column == 0:
+		return fmt.Sprintf("%s:%d", finding.File, finding.Line)
+	}
+	return fmt.Sprintf("%s:%d:%d", finding.File, finding.Line, finding.Column)
+}
+
+// severityColor returns the console color used for a severity.
+func severityColor(severity detectors.Severity) string {
+	switch severity {
+	case detectors.SeverityError:
+		return config.BoldRed
+	case detectors.SeverityWarning:
+		return config.Yellow
+	}
+	return config.Cyan
+}